@@ -24,8 +24,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
+	"time"
 
 	"go.uber.org/dig"
 	"go.uber.org/fx/internal/fxreflect"
@@ -94,6 +96,8 @@ func (a Annotated) String() string {
 	return fmt.Sprintf("fx.Annotated{%v}", strings.Join(fields, ", "))
 }
 
+var _typeOfOut = reflect.TypeOf(Out{})
+
 // field used for embedding fx.Out type in generated struct.
 var _outAnnotationField = reflect.StructField{
 	Name:      "Out",
@@ -125,6 +129,55 @@ func (e *annotationError) Error() string {
 	return e.err.Error()
 }
 
+// report builds the error reported for e when it's passed to the given
+// option.
+func (e annotationError) report(option string, stack fxreflect.Stack) *AnnotationError {
+	name := fxreflect.FuncName(e.target)
+	return &AnnotationError{
+		Option:     option,
+		Annotation: fmt.Sprintf("fx.Annotate(%v)", name),
+		Err: fmt.Errorf("encountered error while applying annotation "+
+			"using fx.Annotate to %s: %w", name, e.err),
+		stack: stack,
+	}
+}
+
+// AnnotationError is the error reported when a function or value passed to
+// Annotate can't be annotated, either because the annotations are invalid or
+// because they don't match the function. It's reported the same way for
+// annotated functions and values passed to Provide, Supply, Decorate,
+// Replace and Invoke, and may be retrieved from the error returned by
+// App.Err with errors.As.
+//
+//	var annErr *fx.AnnotationError
+//	if errors.As(app.Err(), &annErr) {
+//		log.Printf("invalid %v", annErr.Annotation)
+//	}
+type AnnotationError struct {
+	// Option is the name of the option the annotated function or value
+	// was passed to, such as "fx.Provide".
+	Option string
+
+	// Annotation describes the annotated function or value and the
+	// annotations applied to it.
+	Annotation string
+
+	// Err is the reason the annotation failed.
+	Err error
+
+	// Where the option was specified.
+	stack fxreflect.Stack
+}
+
+func (e *AnnotationError) Error() string {
+	return fmt.Sprintf("%v(%v) from:\n%+vFailed: %v", e.Option, e.Annotation, e.stack, e.Err)
+}
+
+// Unwrap returns the reason the annotation failed.
+func (e *AnnotationError) Unwrap() error {
+	return e.Err
+}
+
 type paramTagsAnnotation struct {
 	tags []string
 }
@@ -196,6 +249,62 @@ func ResultTags(tags ...string) Annotation {
 	return resultTagsAnnotation{tags}
 }
 
+type variadicAnnotation struct {
+	empty bool   // whether the variadic parameter is left empty
+	group string // value group for the variadic parameter unless empty
+}
+
+func (va variadicAnnotation) String() string {
+	if va.empty {
+		return "fx.EmptyVariadic()"
+	}
+	return fmt.Sprintf("fx.VariadicGroup(%q)", va.group)
+}
+
+func (va variadicAnnotation) apply(ann *annotated) error {
+	if ann.Variadic != nil {
+		return errors.New("cannot apply more than one of VariadicGroup or EmptyVariadic")
+	}
+	if !va.empty && va.group == "" {
+		return errors.New("fx.VariadicGroup requires a value group name")
+	}
+	ann.Variadic = &va
+	return nil
+}
+
+// VariadicGroup is an Annotation that fills the variadic parameter of a
+// function with the value group of the given name. For example,
+//
+//	fx.Annotate(func(mux *http.ServeMux, handlers ...http.Handler) {
+//	  // ...
+//	}, fx.VariadicGroup("server"))
+//
+// is equivalent to,
+//
+//	fx.Annotate(func(mux *http.ServeMux, handlers ...http.Handler) {
+//	  // ...
+//	}, fx.ParamTags(``, `group:"server"`))
+//
+// VariadicGroup may be combined with ParamTags for the other parameters, but
+// not with a tag for the variadic parameter itself. Annotating a function
+// that isn't variadic, or passing an empty name, fails.
+func VariadicGroup(name string) Annotation {
+	return variadicAnnotation{group: name}
+}
+
+// EmptyVariadic is an Annotation that calls a variadic function without any
+// variadic arguments, as if it had been called in Go code as f(a, b).
+// The function doesn't depend on the slice type of its variadic parameter,
+// even if one is provided to the application.
+//
+//	fx.Provide(fx.Annotate(grpc.NewServer, fx.EmptyVariadic()))
+//
+// Without this annotation, Annotate fills an unannotated variadic parameter
+// with a value of the slice type if the application provides one.
+func EmptyVariadic() Annotation {
+	return variadicAnnotation{empty: true}
+}
+
 type _lifecycleHookAnnotationType int
 
 const (
@@ -205,8 +314,34 @@ const (
 )
 
 type lifecycleHookAnnotation struct {
-	Type   _lifecycleHookAnnotationType
-	Target interface{}
+	Type    _lifecycleHookAnnotationType
+	Target  interface{}
+	Timeout time.Duration
+}
+
+// HookOption configures a lifecycle hook annotation built with OnStart or
+// OnStop.
+type HookOption interface {
+	applyHook(*lifecycleHookAnnotation)
+}
+
+type hookTimeoutOption time.Duration
+
+func (o hookTimeoutOption) applyHook(la *lifecycleHookAnnotation) {
+	la.Timeout = time.Duration(o)
+}
+
+// HookTimeout is a HookOption that limits how long the annotated hook may
+// run. See the OnStartTimeout and OnStopTimeout fields of Hook for details.
+//
+//	fx.Annotate(
+//	  NewServer,
+//	  fx.OnStart(func(ctx context.Context, server Server) error {
+//	    return server.Listen(ctx)
+//	  }, fx.HookTimeout(5*time.Second)),
+//	)
+func HookTimeout(d time.Duration) HookOption {
+	return hookTimeoutOption(d)
 }
 
 func (la *lifecycleHookAnnotation) String() string {
@@ -248,20 +383,10 @@ func (la *lifecycleHookAnnotation) apply(ann *annotated) error {
 		)
 	}
 
-	if ft.NumIn() < 1 || ft.In(0) != _typeOfContext {
-		return fmt.Errorf(
-			"first argument of hook must be context.Context, got %v (%T)",
-			la.Target,
-			la.Target,
-		)
-	}
-
-	hasOut := ft.NumOut() == 1
-	returnsErr := hasOut && ft.Out(0) == _typeOfError
-
-	if !hasOut || !returnsErr {
+	returnsErr := ft.NumOut() == 1 && ft.Out(0) == _typeOfError
+	if ft.NumOut() > 0 && !returnsErr {
 		return fmt.Errorf(
-			"hooks must return only an error type, got %v (%T)",
+			"hooks must return nothing or only an error type, got %v (%T)",
 			la.Target,
 			la.Target,
 		)
@@ -284,6 +409,13 @@ var (
 	_typeOfContext   reflect.Type = reflect.TypeOf((*context.Context)(nil)).Elem()
 )
 
+// takesContext reports whether the hook function accepts a context.Context
+// as its first parameter.
+func (la *lifecycleHookAnnotation) takesContext() bool {
+	ft := reflect.TypeOf(la.Target)
+	return ft.NumIn() > 0 && ft.In(0) == _typeOfContext
+}
+
 type valueResolver func(reflect.Value, int) reflect.Value
 
 func (la *lifecycleHookAnnotation) resolveMap(results []reflect.Type) (
@@ -346,10 +478,16 @@ func (la *lifecycleHookAnnotation) parameters(results ...reflect.Type) (
 		resolve valueResolver
 	}
 
+	// The context.Context, if any, is passed when the hook runs.
+	first := 0
+	if la.takesContext() {
+		first = 1
+	}
+
 	ft := reflect.TypeOf(la.Target)
-	resolverIdx := make([]argSource, 1)
+	resolverIdx := make([]argSource, first)
 
-	for i := 1; i < ft.NumIn(); i++ {
+	for i := first; i < ft.NumIn(); i++ {
 		t := ft.In(i)
 		result, isProvidedByResults := resultMap[t]
 
@@ -392,7 +530,7 @@ func (la *lifecycleHookAnnotation) parameters(results ...reflect.Type) (
 			}
 
 			lc, _ = p.FieldByName("Lifecycle").Interface().(Lifecycle)
-			for i := 1; i < ft.NumIn(); i++ {
+			for i := first; i < ft.NumIn(); i++ {
 				resolver := resolverIdx[i]
 				source := p
 				if resolver.result {
@@ -410,8 +548,10 @@ func (la *lifecycleHookAnnotation) buildHook(fn func(context.Context) error) (ho
 	switch la.Type {
 	case _onStartHookType:
 		hook.OnStart = fn
+		hook.OnStartTimeout = la.Timeout
 	case _onStopHookType:
 		hook.OnStop = fn
+		hook.OnStopTimeout = la.Timeout
 	}
 
 	return
@@ -432,7 +572,9 @@ func (la *lifecycleHookAnnotation) Build(results ...reflect.Type) reflect.Value
 		var lc Lifecycle
 		lc, args = paramMap(args)
 		hookFn := func(ctx context.Context) (err error) {
-			args[0] = reflect.ValueOf(ctx)
+			if la.takesContext() {
+				args[0] = reflect.ValueOf(ctx)
+			}
 
 			results := origFn.Call(args)
 			if len(results) > 0 && results[0].Type() == _typeOfError {
@@ -474,14 +616,24 @@ func (la *lifecycleHookAnnotation) Build(results ...reflect.Type) reflect.Value
 //	   }
 //	 )
 //
+// The hook function may omit the context.Context parameter and the error
+// result, as with StartHook. Its other parameters are filled with the results
+// of the annotated function or from the container.
+//
 // Only one OnStart annotation may be applied to a given function at a time,
 // however functions may be annotated with other types of lifecylce Hooks, such
 // as OnStop.
-func OnStart(onStart interface{}) Annotation {
-	return &lifecycleHookAnnotation{
+//
+// HookOptions such as HookTimeout may be passed after the hook function.
+func OnStart(onStart interface{}, opts ...HookOption) Annotation {
+	la := &lifecycleHookAnnotation{
 		Type:   _onStartHookType,
 		Target: onStart,
 	}
+	for _, opt := range opts {
+		opt.applyHook(la)
+	}
+	return la
 }
 
 // OnStop is an Annotation that appends an OnStop Hook to the application
@@ -509,14 +661,117 @@ func OnStart(onStart interface{}) Annotation {
 //	   }
 //	 )
 //
+// The hook function may omit the context.Context parameter and the error
+// result, as with StopHook. Its other parameters are filled with the results
+// of the annotated function or from the container.
+//
 // Only one OnStop annotation may be applied to a given function at a time,
 // however functions may be annotated with other types of lifecylce Hooks, such
 // as OnStart.
-func OnStop(onStop interface{}) Annotation {
-	return &lifecycleHookAnnotation{
+//
+// HookOptions such as HookTimeout may be passed after the hook function.
+func OnStop(onStop interface{}, opts ...HookOption) Annotation {
+	la := &lifecycleHookAnnotation{
 		Type:   _onStopHookType,
 		Target: onStop,
 	}
+	for _, opt := range opts {
+		opt.applyHook(la)
+	}
+	return la
+}
+
+type autoHooksAnnotation struct{}
+
+func (autoHooksAnnotation) apply(ann *annotated) error {
+	if ann.AutoHooks {
+		return errors.New("cannot apply more than one AutoHooks annotation")
+	}
+	ann.AutoHooks = true
+	return nil
+}
+
+// AutoHooks is an Annotation that appends lifecycle hooks for the results of
+// a function based on the methods they implement:
+//
+//	Start(context.Context) error  // runs on start
+//	Stop(context.Context) error   // runs on stop
+//	Close() error                 // runs on stop, unless Stop is implemented
+//
+// For example, given a *Server with Start and Stop methods,
+//
+//	fx.Provide(fx.Annotate(NewServer, fx.AutoHooks()))
+//
+// is equivalent to,
+//
+//	fx.Provide(func(lc fx.Lifecycle, p Params) *Server {
+//	  server := NewServer(p)
+//	  lc.Append(fx.Hook{OnStart: server.Start, OnStop: server.Stop})
+//	  return server
+//	})
+//
+// The fields of fx.Out structs returned by the function are inspected
+// individually. Nil results are skipped, and no hooks are appended if the
+// function returns an error. The hooks are reported as appended from where
+// the function was passed to Provide, Supply or Decorate.
+//
+// Use SupplyWithHooks to do the same for supplied values.
+func AutoHooks() Annotation {
+	return autoHooksAnnotation{}
+}
+
+type (
+	starter interface{ Start(context.Context) error }
+	stopper interface{ Stop(context.Context) error }
+)
+
+// appendAutoHooks appends the hooks implemented by the given results of a
+// function annotated with AutoHooks.
+func appendAutoHooks(lc Lifecycle, frame fxreflect.Frame, results []reflect.Value) {
+	for _, r := range results {
+		if r.Type() == _typeOfError {
+			continue
+		}
+
+		values := []reflect.Value{r}
+		if isOut(r.Type()) {
+			values = values[:0]
+			for _, f := range flattenFields([]reflect.Type{r.Type()}, isOut) {
+				values = append(values, r.Field(f.Field))
+			}
+		}
+
+		for _, v := range values {
+			if h, ok := autoHook(v); ok {
+				h.callerFrame = frame
+				lc.Append(h)
+			}
+		}
+	}
+}
+
+// autoHook builds a Hook from the methods v implements, if any.
+func autoHook(v reflect.Value) (h Hook, ok bool) {
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		if v.IsNil() {
+			return h, false
+		}
+	}
+
+	value := v.Interface()
+	if s, ok := value.(starter); ok {
+		h.OnStart = s.Start
+	}
+	switch value := value.(type) {
+	case stopper:
+		h.OnStop = value.Stop
+	case io.Closer:
+		h.OnStop = func(context.Context) error {
+			return value.Close()
+		}
+	}
+	return h, h.OnStart != nil || h.OnStop != nil
 }
 
 type asAnnotation struct {
@@ -544,7 +799,7 @@ var _ asAnnotation = asAnnotation{}
 //
 // Note that the bytes.Buffer type is provided as an io.Writer type, so this
 // constructor does NOT provide both bytes.Buffer and io.Writer type; it just
-// provides io.Writer type.
+// provides io.Writer type. Use Self to provide the original type as well.
 //
 // When multiple values are returned by the annotated function, each type
 // gets mapped to corresponding positional result of the annotated function.
@@ -564,13 +819,56 @@ var _ asAnnotation = asAnnotation{}
 //	  w, r := a()
 //	  return w, r
 //	}
+//
+// Each As annotation provides the results it lists once more, so passing As
+// multiple times provides a result as several types. The values are produced
+// by a single call to the function. Results that an As annotation doesn't
+// list are provided as their own types, only once.
+//
+//	fx.Provide(
+//	  fx.Annotate(bytes.NewBuffer(...),
+//	    fx.As(new(io.Writer)),
+//	    fx.As(new(io.Reader)),
+//	  )
+//	)
 func As(interfaces ...interface{}) Annotation {
 	return asAnnotation{interfaces}
 }
 
+// selfType is the type of the value returned by Self.
+type selfType struct{}
+
+// Self returns a value that can be passed to As in place of an interface
+// pointer to provide the corresponding result as its original type.
+//
+// Combined with another As annotation, this provides a result both as its
+// own type and as an interface, from one call to the function. For example,
+// the following provides both *bytes.Buffer and io.Writer, sharing the same
+// buffer:
+//
+//	fx.Provide(
+//	  fx.Annotate(bytes.NewBuffer(...),
+//	    fx.As(fx.Self()),
+//	    fx.As(new(io.Writer)),
+//	  )
+//	)
+//
+// Self may also be used to skip over results when only some of the results
+// of a function should be provided as interfaces.
+//
+//	fx.Annotate(newClientAndServer, fx.As(fx.Self(), new(http.Handler)))
+func Self() interface{} {
+	return selfType{}
+}
+
 func (at asAnnotation) apply(ann *annotated) error {
 	types := make([]reflect.Type, len(at.targets))
 	for i, typ := range at.targets {
+		if _, ok := typ.(selfType); ok {
+			// A nil type keeps the original type of the result.
+			continue
+		}
+
 		t := reflect.TypeOf(typ)
 		if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
 			return fmt.Errorf("fx.As: argument must be a pointer to an interface: got %v", t)
@@ -588,8 +886,13 @@ type annotated struct {
 	ParamTags  []string
 	ResultTags []string
 	As         [][]reflect.Type
+	Variadic   *variadicAnnotation
+	AutoHooks  bool
 	FuncPtr    uintptr
 	Hooks      []*lifecycleHookAnnotation
+
+	// Where the annotated function was passed to Fx, once it's built.
+	Stack fxreflect.Stack
 }
 
 func (ann annotated) String() string {
@@ -602,12 +905,43 @@ func (ann annotated) String() string {
 	if tags := ann.ResultTags; len(tags) > 0 {
 		fmt.Fprintf(&sb, ", fx.ResultTags(%q)", tags)
 	}
-	if as := ann.As; len(as) > 0 {
-		fmt.Fprintf(&sb, ", fx.As(%v)", as)
+	for _, as := range ann.As {
+		types := make([]string, len(as))
+		for i, t := range as {
+			if t == nil {
+				types[i] = "fx.Self()"
+			} else {
+				types[i] = t.String()
+			}
+		}
+		fmt.Fprintf(&sb, ", fx.As(%v)", strings.Join(types, ", "))
+	}
+	if va := ann.Variadic; va != nil {
+		fmt.Fprintf(&sb, ", %v", va)
+	}
+	if ann.AutoHooks {
+		sb.WriteString(", fx.AutoHooks()")
 	}
+	sb.WriteString(")")
 	return sb.String()
 }
 
+// build is Build, reporting errors as an AnnotationError for the given
+// option.
+func (ann *annotated) build(option string, stack fxreflect.Stack) (interface{}, error) {
+	ann.Stack = stack
+	fn, err := ann.Build()
+	if err != nil {
+		return nil, &AnnotationError{
+			Option:     option,
+			Annotation: ann.String(),
+			Err:        err,
+			stack:      stack,
+		}
+	}
+	return fn, nil
+}
+
 // Build builds and returns a constructor based on fx.In/fx.Out params and
 // results wrapping the original constructor passed to fx.Annotate.
 func (ann *annotated) Build() (interface{}, error) {
@@ -647,6 +981,7 @@ func (ann *annotated) Build() (interface{}, error) {
 		} else {
 			results = origFn.Call(args)
 		}
+		origResults := results
 		results = remapResults(results)
 
 		// if the number of results is greater than zero and the final result
@@ -658,6 +993,15 @@ func (ann *annotated) Build() (interface{}, error) {
 			}
 		}
 
+		if ann.AutoHooks {
+			lc, _ := origArgs[0].FieldByName("AutoHooksLifecycle").Interface().(Lifecycle)
+			var frame fxreflect.Frame
+			if len(ann.Stack) > 0 {
+				frame = ann.Stack[0]
+			}
+			appendAutoHooks(lc, frame, origResults)
+		}
+
 		for i, hookFn := range hookFns {
 			hookArgs := hookParams(i, origArgs, results)
 			hookFn.Call(hookArgs)
@@ -669,33 +1013,114 @@ func (ann *annotated) Build() (interface{}, error) {
 	return newFn.Interface(), nil
 }
 
-// checks whether the target function is either
-// returning an fx.Out struct or an taking in a
-// fx.In struct as a parameter.
+// checks that the target function can be annotated: that variadic
+// annotations match its signature, and that the fields of any fx.In or
+// fx.Out structs it uses can be set by the annotated function.
 func (ann *annotated) typeCheckOrigFn() error {
 	ft := reflect.TypeOf(ann.Target)
-	for i := 0; i < ft.NumOut(); i++ {
-		ot := ft.Out(i)
-		if ot.Kind() != reflect.Struct {
-			continue
+	params := make([]reflect.Type, ft.NumIn())
+	for i := range params {
+		params[i] = ft.In(i)
+	}
+	results := make([]reflect.Type, ft.NumOut())
+	for i := range results {
+		results[i] = ft.Out(i)
+	}
+
+	if va := ann.Variadic; va != nil {
+		if !ft.IsVariadic() {
+			return fmt.Errorf("%v requires a variadic function", va)
 		}
-		if dig.IsOut(reflect.New(ft.Out(i)).Elem().Interface()) {
-			return errors.New("fx.Out structs cannot be annotated")
+		if len(ann.ParamTags) >= len(flattenFields(params, isIn)) {
+			return fmt.Errorf("%v cannot be used with a ParamTags tag for the variadic parameter", va)
 		}
 	}
 
-	for i := 0; i < ft.NumIn(); i++ {
-		it := ft.In(i)
-		if it.Kind() != reflect.Struct {
+	for _, t := range params {
+		if !isIn(t) {
 			continue
 		}
-		if dig.IsIn(reflect.New(ft.In(i)).Elem().Interface()) {
-			return errors.New("fx.In structs cannot be annotated")
+		// fx.In structs may opt out of unexported fields, which are then
+		// left unset.
+		ignoreUnexported := false
+		if f, ok := t.FieldByName("In"); ok && f.Anonymous {
+			ignoreUnexported = f.Tag.Get("ignore-unexported") == "true"
+		}
+		if err := checkExportedFields(t, ignoreUnexported); err != nil {
+			return err
+		}
+	}
+	for _, t := range results {
+		if isOut(t) {
+			if err := checkExportedFields(t, false); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+func checkExportedFields(t reflect.Type, ignoreUnexported bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.PkgPath != "" && !ignoreUnexported {
+			return fmt.Errorf("cannot annotate %v: field %v is unexported", t, f.Name)
+		}
+	}
+	return nil
+}
+
+func isIn(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && dig.IsIn(reflect.New(t).Elem().Interface())
+}
+
+func isOut(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && dig.IsOut(reflect.New(t).Elem().Interface())
+}
+
+// annotatedField is a parameter or result of an annotated function, as seen
+// by its annotations. Parameters and results that are fx.In or fx.Out
+// structs are replaced with one annotatedField for each of their fields.
+type annotatedField struct {
+	Type  reflect.Type
+	Tag   reflect.StructTag // tag of the fx.In or fx.Out field, if any
+	Index int               // index of the parameter or result
+	Field int               // index of the field in the struct, or -1
+}
+
+// flattenFields lists the annotatedFields of the given parameter or result
+// types, expanding the types for which isStruct returns true.
+func flattenFields(types []reflect.Type, isStruct func(reflect.Type) bool) []annotatedField {
+	var fields []annotatedField
+	for i, t := range types {
+		if !isStruct(t) {
+			fields = append(fields, annotatedField{Type: t, Index: i, Field: -1})
+			continue
+		}
+		for j := 0; j < t.NumField(); j++ {
+			f := t.Field(j)
+			if f.PkgPath != "" || (f.Anonymous && (f.Type == _typeOfIn || f.Type == _typeOfOut)) {
+				// Skip the embedded fx.In/fx.Out and ignored unexported fields.
+				continue
+			}
+			fields = append(fields, annotatedField{Type: f.Type, Tag: f.Tag, Index: i, Field: j})
+		}
+	}
+	return fields
+}
+
+// mergeTags combines a tag given to ParamTags or ResultTags with the tag
+// of the fx.In or fx.Out field it applies to. Keys of the annotation take
+// precedence.
+func mergeTags(annotation string, field reflect.StructTag) reflect.StructTag {
+	switch {
+	case annotation == "":
+		return field
+	case field == "":
+		return reflect.StructTag(annotation)
+	}
+	return reflect.StructTag(annotation + " " + string(field))
+}
+
 // parameters returns the type for the parameters of the annotated function,
 // and a function that maps the arguments of the annotated function
 // back to the arguments of the target function and a function that maps
@@ -716,7 +1141,7 @@ func (ann *annotated) parameters(results ...reflect.Type) (
 
 	// No parameter annotations. Return the original types
 	// and an identity function.
-	if len(ann.ParamTags) == 0 && !ft.IsVariadic() && len(ann.Hooks) == 0 {
+	if len(ann.ParamTags) == 0 && !ft.IsVariadic() && len(ann.Hooks) == 0 && !ann.AutoHooks {
 		return types, func(args []reflect.Value) []reflect.Value {
 			return args
 		}, nil
@@ -731,15 +1156,28 @@ func (ann *annotated) parameters(results ...reflect.Type) (
 		},
 	}
 
-	for i, t := range types {
+	// Whether the variadic parameter is left out of the fx.In struct and
+	// always passed as an empty slice.
+	emptyVariadic := ann.Variadic != nil && ann.Variadic.empty
+
+	fields := flattenFields(types, isIn)
+	for i, f := range fields {
+		isVariadic := f.Index == ft.NumIn()-1 && ft.IsVariadic()
+		if isVariadic && emptyVariadic {
+			continue
+		}
+
 		field := reflect.StructField{
 			Name: fmt.Sprintf("Field%d", i),
-			Type: t,
+			Type: f.Type,
+			Tag:  f.Tag,
 		}
 
 		if i < len(ann.ParamTags) {
-			field.Tag = reflect.StructTag(ann.ParamTags[i])
-		} else if i == ft.NumIn()-1 && ft.IsVariadic() {
+			field.Tag = mergeTags(ann.ParamTags[i], f.Tag)
+		} else if isVariadic && ann.Variadic != nil {
+			field.Tag = reflect.StructTag(fmt.Sprintf(`group:"%v"`, ann.Variadic.group))
+		} else if isVariadic {
 			// If a variadic argument is unannotated, mark it optional,
 			// so that just wrapping a function in fx.Annotate does not
 			// suddenly introduce a required []arg dependency.
@@ -760,12 +1198,35 @@ func (ann *annotated) parameters(results ...reflect.Type) (
 		inFields = append(inFields, field)
 	}
 
+	if ann.AutoHooks {
+		inFields = append(inFields, reflect.StructField{
+			Name: "AutoHooksLifecycle",
+			Type: _typeOfLifecycle,
+		})
+	}
+
 	types = []reflect.Type{reflect.StructOf(inFields)}
 	remap = func(args []reflect.Value) []reflect.Value {
 		params := args[0]
-		args = args[:0]
-		for i := 0; i < ft.NumIn(); i++ {
-			args = append(args, params.Field(i+1))
+		args = make([]reflect.Value, ft.NumIn())
+		for i := range args {
+			if isIn(ft.In(i)) {
+				args[i] = reflect.New(ft.In(i)).Elem()
+			}
+		}
+		for i, f := range fields {
+			var v reflect.Value
+			if i == len(fields)-1 && emptyVariadic {
+				v = reflect.Zero(f.Type)
+			} else {
+				v = params.Field(i + 1)
+			}
+
+			if f.Field < 0 {
+				args[f.Index] = v
+			} else {
+				args[f.Index].Field(f.Field).Set(v)
+			}
 		}
 		return args
 	}
@@ -820,9 +1281,10 @@ func (ann *annotated) results() (
 
 	type outStructInfo struct {
 		Fields  []reflect.StructField // fields of the struct
-		Offsets []int                 // Offsets[i] is the index of result i in Fields
+		Offsets []int                 // Offsets[i] is the index of field i in Fields
 	}
 
+	fields := flattenFields(types, isOut)
 	outs := make([]outStructInfo, numStructs)
 
 	for i := 0; i < numStructs; i++ {
@@ -833,21 +1295,26 @@ func (ann *annotated) results() (
 				Anonymous: true,
 			},
 		}
-		outs[i].Offsets = make([]int, len(types))
+		outs[i].Offsets = make([]int, len(fields))
 	}
 
 	var hasError bool
 
-	for i, t := range types {
-		if t == _typeOfError {
+	// Fields that an As annotation doesn't list are provided as their own
+	// type, but only by the first such annotation.
+	keptType := make([]bool, len(fields))
+
+	for i, f := range fields {
+		t := f.Type
+		if t == _typeOfError && f.Field < 0 {
 			// Guarantee that:
 			// - only the last result is an error
 			// - there is at most one error result
-			if i != len(types)-1 {
+			if f.Index != len(types)-1 {
 				return nil, nil, fmt.Errorf(
 					"only the last result can be an error: "+
 						"%v (%v) returns error as result %d",
-					fxreflect.FuncName(ann.Target), ft, i)
+					fxreflect.FuncName(ann.Target), ft, f.Index)
 			}
 			hasError = true
 			continue
@@ -857,16 +1324,23 @@ func (ann *annotated) results() (
 			field := reflect.StructField{
 				Name: fmt.Sprintf("Field%d", i),
 				Type: t,
+				Tag:  f.Tag,
 			}
 
-			if len(ann.As) > 0 && i < len(ann.As[j]) {
+			if len(ann.As) > 0 && i >= len(ann.As[j]) {
+				if keptType[i] {
+					continue
+				}
+				keptType[i] = true
+			}
+			if len(ann.As) > 0 && i < len(ann.As[j]) && ann.As[j][i] != nil {
 				if !t.Implements(ann.As[j][i]) {
-					return nil, nil, fmt.Errorf("invalid fx.As: %v does not implement %v", t, ann.As[i])
+					return nil, nil, fmt.Errorf("invalid fx.As: %v does not implement %v", t, ann.As[j][i])
 				}
 				field.Type = ann.As[j][i]
 			}
 			if i < len(ann.ResultTags) {
-				field.Tag = reflect.StructTag(ann.ResultTags[i])
+				field.Tag = mergeTags(ann.ResultTags[i], f.Tag)
 			}
 			outs[j].Offsets[i] = len(outs[j].Fields)
 			outs[j].Fields = append(outs[j].Fields, field)
@@ -884,24 +1358,15 @@ func (ann *annotated) results() (
 	}
 
 	return outTypes, func(results []reflect.Value) []reflect.Value {
-		var (
-			outErr     error
-			outResults []reflect.Value
-		)
-
+		var outResults []reflect.Value
 		for _, resType := range resTypes {
 			outResults = append(outResults, reflect.New(resType).Elem())
 		}
 
-		for i, r := range results {
-			if i == len(results)-1 && hasError {
-				// If hasError and this is the last item,
-				// we are guaranteed that this is an error
-				// object.
-				if err, _ := r.Interface().(error); err != nil {
-					outErr = err
-				}
-				continue
+		for i, f := range fields {
+			r := results[f.Index]
+			if f.Field >= 0 {
+				r = r.Field(f.Field)
 			}
 			for j := range resTypes {
 				if fieldIdx := outs[j].Offsets[i]; fieldIdx > 0 {
@@ -910,15 +1375,17 @@ func (ann *annotated) results() (
 					// outs and would point to fx.Out in the
 					// struct definition. We need to check this
 					// to prevent panic from setting fx.Out to
-					// a value.
+					// a value. This also skips the error result.
 					outResults[j].Field(fieldIdx).Set(r)
 				}
 			}
 		}
 
 		if hasError {
-			if outErr != nil {
-				outResults = append(outResults, reflect.ValueOf(outErr))
+			// If hasError, we are guaranteed that the last
+			// result is an error object.
+			if err, _ := results[len(results)-1].Interface().(error); err != nil {
+				outResults = append(outResults, reflect.ValueOf(err))
 			} else {
 				outResults = append(outResults, _nilError)
 			}
@@ -979,6 +1446,41 @@ func (ann *annotated) results() (
 // If more tags are given than the number of parameters/results, only
 // the ones up to the number of parameters/results will be applied.
 //
+// # fx.In and fx.Out structs
+//
+// Functions that accept fx.In structs or return fx.Out structs may be
+// annotated too. Annotations see the fields of these structs as if they were
+// separate parameters or results, in order. For example, given,
+//
+//	type Params struct {
+//	  fx.In
+//
+//	  Logger *zap.Logger
+//	  Config Config `optional:"true"`
+//	}
+//
+//	type Result struct {
+//	  fx.Out
+//
+//	  Handler *Handler
+//	  Metrics *Metrics
+//	}
+//
+//	func NewHandler(p Params) (Result, error) { ... }
+//
+// The following provides the handler as an http.Handler, and reads the
+// configuration from a named value:
+//
+//	fx.Annotate(
+//	  NewHandler,
+//	  fx.ParamTags(``, `name:"handler"`),
+//	  fx.As(new(http.Handler)),
+//	)
+//
+// Tags given to ParamTags and ResultTags are combined with the tags of the
+// fields, and take precedence over them. The Config field above is
+// therefore both named and optional.
+//
 // # Variadic functions
 //
 // If the provided function is variadic, Annotate treats its parameter as a
@@ -994,6 +1496,10 @@ func (ann *annotated) results() (
 //	  // ...
 //	}, ...)
 //
+// An unannotated variadic parameter is optional: it's filled with a value of
+// the slice type if the application provides one, and left empty otherwise.
+// Use EmptyVariadic to always leave it empty.
+//
 // You can use variadic parameters with Fx's value groups.
 // For example,
 //
@@ -1001,6 +1507,12 @@ func (ann *annotated) results() (
 //	  // ...
 //	}, fx.ParamTags(``, `group:"server"`))
 //
+// Or, without listing tags for the other parameters,
+//
+//	fx.Annotate(func(mux *http.ServeMux, handlers ...http.Handler) {
+//	  // ...
+//	}, fx.VariadicGroup("server"))
+//
 // If we provide the above to the application,
 // any constructor in the Fx application can inject its HTTP handlers
 // by using fx.Annotate, fx.Annotated, or fx.Out.
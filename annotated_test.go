@@ -28,6 +28,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -205,6 +206,44 @@ func TestAnnotatedAs(t *testing.T) {
 				assert.Equal(t, "foo", s.String())
 			},
 		},
+		{
+			desc: "provide the original type alongside an interface with fx.Self",
+			provide: fx.Provide(
+				fx.Annotate(newAsStringer,
+					fx.As(fx.Self()),
+					fx.As(new(fmt.Stringer))),
+			),
+			invoke: func(s fmt.Stringer, as *asStringer) {
+				assert.Same(t, as, s, "must be built only once")
+			},
+		},
+		{
+			desc: "keep some results with fx.Self",
+			provide: fx.Provide(
+				fx.Annotate(func() (*asStringer, *bytes.Buffer) {
+					return &asStringer{name: "stringer"}, new(bytes.Buffer)
+				}, fx.As(fx.Self(), new(io.Writer))),
+			),
+			invoke: func(as *asStringer, w io.Writer) {
+				assert.Equal(t, "stringer", as.String())
+				assert.NotNil(t, w)
+			},
+		},
+		{
+			desc: "results not listed by multiple As are provided once",
+			provide: fx.Provide(
+				fx.Annotate(func() (*asStringer, string) {
+					return &asStringer{name: "stringer"}, "foo"
+				},
+					fx.As(new(fmt.Stringer)),
+					fx.As(new(myStringer))),
+			),
+			invoke: func(s fmt.Stringer, ms myStringer, str string) {
+				assert.Equal(t, "stringer", s.String())
+				assert.Equal(t, "stringer", ms.String())
+				assert.Equal(t, "foo", str)
+			},
+		},
 		{
 			desc: "annotate as many interfaces",
 			provide: fx.Provide(
@@ -730,6 +769,115 @@ func TestAnnotate(t *testing.T) {
 		require.NoError(t, app.Err())
 	})
 
+	t.Run("Provide variadic function with VariadicGroup", func(t *testing.T) {
+		t.Parallel()
+
+		var got *sliceA
+		app := fxtest.New(t,
+			fx.Provide(
+				fx.Annotated{Group: "as", Target: newA},
+				fx.Annotated{Group: "as", Target: newA},
+				fx.Annotate(newSliceA, fx.VariadicGroup("as")),
+			),
+			fx.Populate(&got),
+		)
+		defer app.RequireStart().RequireStop()
+
+		assert.Len(t, got.sa, 2)
+	})
+
+	t.Run("Invoke variadic function with VariadicGroup and ParamTags", func(t *testing.T) {
+		t.Parallel()
+
+		app := fxtest.New(t,
+			fx.Provide(
+				fx.Annotated{Group: "as", Target: newA},
+				fx.Annotate(func() *b { return newB(newA()) }, fx.ResultTags(`name:"b"`)),
+			),
+			fx.Invoke(fx.Annotate(
+				func(b *b, as ...*a) {
+					assert.NotNil(t, b)
+					assert.Len(t, as, 1)
+				},
+				fx.ParamTags(`name:"b"`),
+				fx.VariadicGroup("as"),
+			)),
+		)
+		defer app.RequireStart().RequireStop()
+	})
+
+	t.Run("Provide variadic function with EmptyVariadic", func(t *testing.T) {
+		t.Parallel()
+
+		var got *sliceA
+		app := fxtest.New(t,
+			fx.Supply([]*a{{}, {}, {}}),
+			fx.Provide(fx.Annotate(newSliceA, fx.EmptyVariadic())),
+			fx.Populate(&got),
+		)
+		defer app.RequireStart().RequireStop()
+
+		assert.Empty(t, got.sa)
+	})
+
+	t.Run("EmptyVariadic with hooks", func(t *testing.T) {
+		t.Parallel()
+
+		var started bool
+		app := fxtest.New(t,
+			fx.Provide(fx.Annotate(newSliceA,
+				fx.EmptyVariadic(),
+				fx.OnStart(func(_ context.Context, sa *sliceA) error {
+					started = true
+					assert.Empty(t, sa.sa)
+					return nil
+				}),
+			)),
+			fx.Invoke(func(*sliceA) {}),
+		)
+		app.RequireStart().RequireStop()
+
+		assert.True(t, started)
+	})
+
+	t.Run("invalid variadic annotations", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc    string
+			target  interface{}
+			wantErr string
+		}{
+			{
+				desc:    "not variadic",
+				target:  fx.Annotate(newA, fx.VariadicGroup("as")),
+				wantErr: `fx.VariadicGroup("as") requires a variadic function`,
+			},
+			{
+				desc:    "variadic parameter tagged",
+				target:  fx.Annotate(newSliceA, fx.EmptyVariadic(), fx.ParamTags(`group:"as"`)),
+				wantErr: "fx.EmptyVariadic() cannot be used with a ParamTags tag for the variadic parameter",
+			},
+			{
+				desc:    "applied twice",
+				target:  fx.Annotate(newSliceA, fx.EmptyVariadic(), fx.VariadicGroup("as")),
+				wantErr: "cannot apply more than one of VariadicGroup or EmptyVariadic",
+			},
+			{
+				desc:    "empty group name",
+				target:  fx.Annotate(newSliceA, fx.VariadicGroup("")),
+				wantErr: "fx.VariadicGroup requires a value group name",
+			},
+		}
+
+		for _, tt := range tests {
+			app := NewForTest(t, fx.Provide(tt.target))
+			err := app.Err()
+			require.Error(t, err, tt.desc)
+			assert.Contains(t, err.Error(), tt.wantErr, tt.desc)
+		}
+	})
+
 	t.Run("provide with annotated results", func(t *testing.T) {
 		t.Parallel()
 
@@ -978,7 +1126,7 @@ func TestAnnotate(t *testing.T) {
 		assert.Contains(t, err.Error(), "must provide constructor function, got 42 (int)")
 	})
 
-	t.Run("annotate a fx.Out", func(t *testing.T) {
+	t.Run("annotate a fx.Out with unexported fields", func(t *testing.T) {
 		t.Parallel()
 
 		type A struct {
@@ -999,7 +1147,66 @@ func TestAnnotate(t *testing.T) {
 
 		err := app.Err()
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "fx.Out structs cannot be annotated")
+		assert.Contains(t, err.Error(), "cannot annotate fx_test.A: field s is unexported")
+	})
+
+	t.Run("annotate a fx.Out", func(t *testing.T) {
+		t.Parallel()
+
+		type Result struct {
+			fx.Out
+
+			Buffer *bytes.Buffer
+			Name   string `name:"name"`
+		}
+
+		var got struct {
+			fx.In
+
+			Writer io.Writer     `name:"w"`
+			Buffer *bytes.Buffer `name:"w"`
+			Name   string        `name:"name"`
+		}
+		app := fxtest.New(t,
+			fx.Provide(
+				fx.Annotate(
+					func() (Result, error) {
+						return Result{Buffer: new(bytes.Buffer), Name: "foo"}, nil
+					},
+					fx.ResultTags(`name:"w"`),
+					fx.As(new(io.Writer)),
+					fx.As(fx.Self()),
+				),
+			),
+			fx.Populate(&got),
+		)
+		defer app.RequireStart().RequireStop()
+
+		assert.Same(t, got.Buffer, got.Writer)
+		assert.Equal(t, "foo", got.Name)
+	})
+
+	t.Run("annotate a fx.Out that fails", func(t *testing.T) {
+		t.Parallel()
+
+		type Result struct {
+			fx.Out
+
+			Name string
+		}
+
+		app := NewForTest(t,
+			fx.Provide(
+				fx.Annotate(
+					func() (Result, error) { return Result{}, errors.New("great sadness") },
+					fx.ResultTags(`name:"foo"`),
+				),
+			),
+			fx.Invoke(fx.Annotate(func(string) {}, fx.ParamTags(`name:"foo"`))),
+		)
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
 	})
 
 	t.Run("annotate a fx.In", func(t *testing.T) {
@@ -1012,20 +1219,186 @@ func TestAnnotate(t *testing.T) {
 			fx.In
 		}
 
-		app := NewForTest(t,
+		var got string
+		app := fxtest.New(t,
 			fx.Provide(
 				fx.Annotate(func(i A) string { return i.S }, fx.ParamTags(`optional:"true"`)),
-				fx.Annotate(func(i B) string { return "ok" }, fx.ParamTags(`name:"problem"`)),
+				fx.Annotate(func(i B) string { return "ok" }, fx.ParamTags(`name:"problem"`), fx.ResultTags(`name:"b"`)),
+			),
+			fx.Invoke(fx.Annotate(func(s string) { got = s }, fx.ParamTags(`name:"b"`))),
+		)
+		defer app.RequireStart().RequireStop()
+
+		assert.Equal(t, "ok", got)
+	})
+
+	t.Run("annotate a fx.In with tags and hooks", func(t *testing.T) {
+		t.Parallel()
+
+		type Params struct {
+			fx.In `ignore-unexported:"true"`
+
+			Buffer *bytes.Buffer
+			Name   string `optional:"true"`
+
+			ignored string
+		}
+
+		type Result struct {
+			fx.Out
+
+			Name string `name:"result"`
+		}
+
+		var (
+			started bool
+			got     struct {
+				fx.In
+
+				Name string `name:"result"`
+			}
+		)
+		app := fxtest.New(t,
+			fx.Supply(new(bytes.Buffer)),
+			fx.Provide(
+				fx.Annotate(func() string { return "foo" }, fx.ResultTags(`name:"name"`)),
+				fx.Annotate(
+					func(p Params) Result {
+						assert.NotNil(t, p.Buffer)
+						return Result{Name: p.Name}
+					},
+					fx.ParamTags(``, `name:"name"`),
+					fx.OnStart(func(context.Context) error {
+						started = true
+						return nil
+					}),
+				),
 			),
+			fx.Populate(&got),
+		)
+		app.RequireStart().RequireStop()
+
+		assert.True(t, started)
+		assert.Equal(t, "foo", got.Name)
+	})
+
+	t.Run("annotate a fx.In with unexported fields", func(t *testing.T) {
+		t.Parallel()
+
+		type Params struct {
+			fx.In
+
+			s string
+		}
+
+		app := NewForTest(t,
+			fx.Invoke(fx.Annotate(func(Params) {}, fx.ParamTags(`optional:"true"`))),
 		)
 		err := app.Err()
 		require.Error(t, err)
-		assert.NotContains(t, err.Error(), "invalid annotation function func(fx_test.A) string")
-		assert.Contains(t, err.Error(), "invalid annotation function func(fx_test.B) string")
-		assert.Contains(t, err.Error(), "fx.In structs cannot be annotated")
+		assert.Contains(t, err.Error(), "cannot annotate fx_test.Params: field s is unexported")
 	})
 }
 
+func TestAnnotationError(t *testing.T) {
+	t.Parallel()
+
+	newBuffer := func() *bytes.Buffer { return new(bytes.Buffer) }
+	useBuffer := func(*bytes.Buffer) {}
+
+	// Fails when applied by fx.Annotate rather than when it's built.
+	invalid := fx.Annotate(newBuffer, fx.ParamTags(`name:"a"`), fx.ParamTags(`name:"b"`))
+
+	tests := []struct {
+		desc       string
+		opt        fx.Option
+		option     string
+		annotation string
+		wantErr    string
+	}{
+		{
+			desc:       "Provide/invalid",
+			opt:        fx.Provide(invalid),
+			option:     "fx.Provide",
+			annotation: "fx.Annotate(go.uber.org/fx_test.TestAnnotationError.func1())",
+			wantErr:    "cannot apply more than one line of ParamTags",
+		},
+		{
+			desc:       "Provide/mismatched",
+			opt:        fx.Provide(fx.Annotate(newBuffer, fx.As(new(io.Closer)))),
+			option:     "fx.Provide",
+			annotation: "fx.As(io.Closer)",
+			wantErr:    "*bytes.Buffer does not implement io.Closer",
+		},
+		{
+			desc:       "Supply/invalid",
+			opt:        fx.Supply(fx.Annotate(new(bytes.Buffer), fx.ResultTags(`name:"a"`), fx.ResultTags(`name:"b"`))),
+			option:     "fx.Supply",
+			annotation: "fx.Annotate(",
+			wantErr:    "cannot apply more than one line of ResultTags",
+		},
+		{
+			desc:       "Decorate/invalid",
+			opt:        fx.Decorate(invalid),
+			option:     "fx.Decorate",
+			annotation: "fx.Annotate(go.uber.org/fx_test.TestAnnotationError.func1())",
+			wantErr:    "cannot apply more than one line of ParamTags",
+		},
+		{
+			desc:       "Decorate/mismatched",
+			opt:        fx.Decorate(fx.Annotate(func(b *bytes.Buffer) *bytes.Buffer { return b }, fx.As(new(io.Closer)))),
+			option:     "fx.Decorate",
+			annotation: "fx.As(io.Closer)",
+			wantErr:    "*bytes.Buffer does not implement io.Closer",
+		},
+		{
+			desc:       "Replace/invalid",
+			opt:        fx.Replace(fx.Annotate(new(bytes.Buffer), fx.As(new(io.Reader)), fx.ResultTags(`name:"a"`), fx.ResultTags(`name:"b"`))),
+			option:     "fx.Replace",
+			annotation: "fx.Annotate(",
+			wantErr:    "cannot apply more than one line of ResultTags",
+		},
+		{
+			desc:       "Invoke/invalid",
+			opt:        fx.Invoke(fx.Annotate(useBuffer, fx.ParamTags(`name:"a"`), fx.ParamTags(`name:"b"`))),
+			option:     "fx.Invoke",
+			annotation: "fx.Annotate(go.uber.org/fx_test.TestAnnotationError.func2())",
+			wantErr:    "cannot apply more than one line of ParamTags",
+		},
+		{
+			desc:       "Invoke/mismatched",
+			opt:        fx.Invoke(fx.Annotate(useBuffer, fx.VariadicGroup("buffers"))),
+			option:     "fx.Invoke",
+			annotation: `fx.VariadicGroup("buffers")`,
+			wantErr:    `fx.VariadicGroup("buffers") requires a variadic function`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			app := NewForTest(t,
+				fx.Provide(newBuffer),
+				tt.opt,
+			)
+			err := app.Err()
+			require.Error(t, err)
+
+			var annErr *fx.AnnotationError
+			require.ErrorAs(t, err, &annErr)
+			assert.Equal(t, tt.option, annErr.Option)
+			assert.Contains(t, annErr.Annotation, tt.annotation)
+			assert.Contains(t, annErr.Err.Error(), tt.wantErr)
+
+			// The error points at the option that received the annotation.
+			assert.Contains(t, err.Error(), tt.option+"(")
+			assert.Contains(t, err.Error(), "annotated_test.go")
+		})
+	}
+}
+
 func assertApp(
 	t *testing.T,
 	app interface {
@@ -1075,6 +1448,47 @@ func TestHookAnnotations(t *testing.T) {
 		assertApp(t, app, &started, nil, &invoked)
 	})
 
+	t.Run("with hooks in other shapes", func(t *testing.T) {
+		t.Parallel()
+
+		type A struct{ name string }
+
+		var started, stopped string
+		app := fxtest.New(t,
+			fx.Provide(fx.Annotate(
+				func() *A { return &A{name: "foo"} },
+				fx.OnStart(func(a *A) { started = a.name }),
+				fx.OnStop(func(a *A) error {
+					stopped = a.name
+					return nil
+				}),
+			)),
+			fx.Invoke(func(*A) {}),
+		)
+		app.RequireStart().RequireStop()
+
+		assert.Equal(t, "foo", started)
+		assert.Equal(t, "foo", stopped)
+	})
+
+	t.Run("with hook without error result", func(t *testing.T) {
+		t.Parallel()
+
+		var started bool
+		app := fxtest.New(t,
+			fx.Invoke(fx.Annotate(
+				func() {},
+				fx.OnStart(func(ctx context.Context) {
+					assert.NotNil(t, ctx)
+					started = true
+				}),
+			)),
+		)
+		app.RequireStart().RequireStop()
+
+		assert.True(t, started)
+	})
+
 	t.Run("depend on result interface of target", func(t *testing.T) {
 		type stub interface {
 			String() string
@@ -1137,6 +1551,31 @@ func TestHookAnnotations(t *testing.T) {
 		assertApp(t, app, &started, &stopped, &invoked)
 	})
 
+	t.Run("with hook timeout", func(t *testing.T) {
+		t.Parallel()
+
+		type stub interface{}
+
+		hook := fx.Annotate(
+			func() stub { return nil },
+			fx.OnStart(func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}, fx.HookTimeout(time.Millisecond)),
+		)
+
+		app := fxtest.New(t,
+			fx.Provide(hook),
+			fx.Invoke(func(stub) {}),
+		)
+
+		err := app.Start(context.Background())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "OnStart hook added by")
+		assert.Contains(t, err.Error(), "timed out after 1ms")
+	})
+
 	t.Run("with multiple extra dependency parameters", func(t *testing.T) {
 		t.Parallel()
 
@@ -1320,6 +1759,141 @@ func TestHookAnnotations(t *testing.T) {
 	})
 }
 
+type autoHooksServer struct {
+	started, stopped bool
+}
+
+func (s *autoHooksServer) Start(context.Context) error {
+	s.started = true
+	return nil
+}
+
+func (s *autoHooksServer) Stop(context.Context) error {
+	s.stopped = true
+	return nil
+}
+
+type autoHooksConn struct {
+	closed bool
+}
+
+func (c *autoHooksConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestAutoHooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("start and stop", func(t *testing.T) {
+		t.Parallel()
+
+		var server *autoHooksServer
+		app, spy := NewSpied(
+			fx.Provide(fx.Annotate(func() *autoHooksServer { return new(autoHooksServer) }, fx.AutoHooks())),
+			fx.Populate(&server),
+		)
+		require.NoError(t, app.Err())
+
+		require.NoError(t, app.Start(context.Background()))
+		assert.True(t, server.started)
+		assert.False(t, server.stopped)
+		require.NoError(t, app.Stop(context.Background()))
+		assert.True(t, server.stopped)
+
+		// Hooks are attributed to the function that called fx.Provide.
+		executing := spy.Events().SelectByTypeName("OnStartExecuting")
+		require.Equal(t, 1, executing.Len())
+		assert.Equal(t, "go.uber.org/fx_test.TestAutoHooks.func1",
+			executing[0].(*fxevent.OnStartExecuting).CallerName)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		t.Parallel()
+
+		var conn *autoHooksConn
+		app := fxtest.New(t,
+			fx.Provide(fx.Annotate(func() (*autoHooksConn, error) { return new(autoHooksConn), nil }, fx.AutoHooks())),
+			fx.Populate(&conn),
+		)
+		app.RequireStart().RequireStop()
+
+		assert.True(t, conn.closed)
+	})
+
+	t.Run("fx.Out fields", func(t *testing.T) {
+		t.Parallel()
+
+		type result struct {
+			fx.Out
+
+			Server *autoHooksServer
+			Conn   *autoHooksConn `name:"conn"`
+			Nil    *autoHooksConn `name:"nil"`
+		}
+
+		var got struct {
+			fx.In
+
+			Server *autoHooksServer
+			Conn   *autoHooksConn `name:"conn"`
+		}
+		app := fxtest.New(t,
+			fx.Provide(fx.Annotate(func() result {
+				return result{Server: new(autoHooksServer), Conn: new(autoHooksConn)}
+			}, fx.AutoHooks())),
+			fx.Populate(&got),
+		)
+		app.RequireStart().RequireStop()
+
+		assert.True(t, got.Server.started)
+		assert.True(t, got.Server.stopped)
+		assert.True(t, got.Conn.closed)
+	})
+
+	t.Run("in a module", func(t *testing.T) {
+		t.Parallel()
+
+		app, spy := NewSpied(
+			fx.Module("server",
+				fx.Provide(fx.Annotate(func() *autoHooksServer { return new(autoHooksServer) }, fx.AutoHooks())),
+				fx.Invoke(func(*autoHooksServer) {}),
+			),
+		)
+		require.NoError(t, app.Start(context.Background()))
+		require.NoError(t, app.Stop(context.Background()))
+
+		executing := spy.Events().SelectByTypeName("OnStartExecuting")
+		require.Equal(t, 1, executing.Len())
+		assert.Equal(t, "server", executing[0].(*fxevent.OnStartExecuting).ModuleName)
+	})
+
+	t.Run("not for failed constructors", func(t *testing.T) {
+		t.Parallel()
+
+		conn := new(autoHooksConn)
+		app := NewForTest(t,
+			fx.Provide(fx.Annotate(func() (*autoHooksConn, error) {
+				return conn, errors.New("great sadness")
+			}, fx.AutoHooks())),
+			fx.Invoke(func(*autoHooksConn) {}),
+		)
+		require.Error(t, app.Err())
+		assert.False(t, conn.closed)
+	})
+
+	t.Run("applied twice", func(t *testing.T) {
+		t.Parallel()
+
+		app := NewForTest(t,
+			fx.Provide(fx.Annotate(func() *autoHooksConn { return nil }, fx.AutoHooks(), fx.AutoHooks())),
+		)
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot apply more than one AutoHooks annotation")
+	})
+}
+
 func TestHookAnnotationFailures(t *testing.T) {
 	t.Parallel()
 	validateApp := func(t *testing.T, opts ...fx.Option) error {
@@ -1370,11 +1944,11 @@ func TestHookAnnotationFailures(t *testing.T) {
 			),
 		},
 		{
-			name:        "with hook that doesn't return an error",
-			errContains: "must return only an error",
+			name:        "with hook that returns a value",
+			errContains: "must return nothing or only an error",
 			annotation: fx.Annotate(
 				func() A { return nil },
-				fx.OnStart(func(context.Context) {}),
+				fx.OnStart(func(context.Context) int { return 0 }),
 			),
 		},
 		{
@@ -1399,14 +1973,6 @@ func TestHookAnnotationFailures(t *testing.T) {
 				fx.OnStart(&struct{}{}),
 			),
 		},
-		{
-			name:        "without context.Context as first parameter",
-			errContains: "must be context.Context",
-			annotation: fx.Annotate(
-				func() A { return nil },
-				fx.OnStart(func() {}),
-			),
-		},
 		{
 			name:        "with variactic hook",
 			errContains: "must not accept variatic",
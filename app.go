@@ -26,11 +26,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/signal"
 	"reflect"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/dig"
@@ -167,6 +167,28 @@ func (t stopTimeoutOption) String() string {
 	return fmt.Sprintf("fx.StopTimeout(%v)", time.Duration(t))
 }
 
+// SlowHookThreshold makes the application report every OnStart and OnStop
+// hook that runs for longer than the given threshold with an fxevent.SlowHook
+// event. Slow hooks aren't reported by default.
+func SlowHookThreshold(v time.Duration) Option {
+	return slowHookThresholdOption(v)
+}
+
+type slowHookThresholdOption time.Duration
+
+func (t slowHookThresholdOption) apply(m *module) {
+	if m.parent != nil {
+		m.app.err = fmt.Errorf("fx.SlowHookThreshold Option should be passed to top-level App, " +
+			"not to fx.Module")
+	} else {
+		m.app.slowHookThreshold = time.Duration(t)
+	}
+}
+
+func (t slowHookThresholdOption) String() string {
+	return fmt.Sprintf("fx.SlowHookThreshold(%v)", time.Duration(t))
+}
+
 // WithLogger specifies how Fx should build an fxevent.Logger to log its events
 // to. The argument must be a constructor with one of the following return
 // types.
@@ -179,6 +201,13 @@ func (t stopTimeoutOption) String() string {
 //	WithLogger(func(logger *zap.Logger) fxevent.Logger {
 //	  return &fxevent.ZapLogger{Logger: logger}
 //	})
+//
+// When passed to an fx.Module, WithLogger sets the logger only for events
+// about that module and its children: the values they provide, decorate, and
+// invoke. The constructor may depend on values available inside the module.
+// Modules without a logger of their own use their parent's. Events about the
+// application as a whole, such as lifecycle hooks, always go to the
+// application's logger.
 func WithLogger(constructor interface{}) Option {
 	return withLoggerOption{
 		constructor: constructor,
@@ -192,15 +221,14 @@ type withLoggerOption struct {
 }
 
 func (l withLoggerOption) apply(m *module) {
+	p := &provide{
+		Target: l.constructor,
+		Stack:  l.Stack,
+	}
 	if m.parent != nil {
-		// loggers shouldn't differ based on Module.
-		m.app.err = fmt.Errorf("fx.WithLogger Option should be passed to top-level App, " +
-			"not to fx.Module")
+		m.logConstructor = p
 	} else {
-		m.app.logConstructor = &provide{
-			Target: l.constructor,
-			Stack:  l.Stack,
-		}
+		m.app.logConstructor = p
 	}
 }
 
@@ -246,7 +274,7 @@ var NopLogger = WithLogger(func() fxevent.Logger { return fxevent.NopLogger })
 // An App is a modular application built around dependency injection. Most
 // users will only need to use the New constructor and the all-in-one Run
 // convenience method. In more unusual cases, users may need to use the Err,
-// Start, Done, and Stop methods by hand instead of relying on Run.
+// Start, Done (or Wait), and Stop methods by hand instead of relying on Run.
 //
 // New creates and initializes an App. All applications begin with a
 // constructor for the Lifecycle type already registered.
@@ -277,6 +305,11 @@ var NopLogger = WithLogger(func() fxevent.Logger { return fxevent.NopLogger })
 // Start, it will operate until the user calls Stop. On shutdown, OnStop hooks
 // execute one at a time, in reverse order, and must all complete within a
 // configurable deadline (again, 15 seconds by default).
+//
+// A stopped application may be started again with Start. The restarted
+// application runs the same OnStart and OnStop hooks again, and waits for
+// a new shutdown signal: Done and Wait channels obtained before Stop don't
+// receive signals for the restarted application.
 type App struct {
 	err       error
 	clock     fxclock.Clock
@@ -292,13 +325,31 @@ type App struct {
 	// Timeouts used
 	startTimeout time.Duration
 	stopTimeout  time.Duration
+	// Hooks that run for longer than this are reported as slow.
+	slowHookThreshold time.Duration
 	// Decides how we react to errors when building the graph.
-	errorHooks []ErrorHandler
-	validate   bool
+	validate bool
+	// Used to run lifecycle hooks in parallel.
+	parallelHooks bool
+	hookGraph     *hookGraph // nil unless parallelHooks is set
+	// Used to decide when the application is ready.
+	readiness *readiness
+	runner    *runner
+	// Used to handle operating system signals.
+	shutdownSignals []os.Signal
+	signalHandlers  *signalHandlers
+	// Serializes container access after New returns, by constructors
+	// wrapped with Lazy and by Resolve. Use withContainer to hold it.
+	containerMu   sync.Mutex
+	containerHeld int32 // 1 while containerMu is held; accessed atomically
 	// Used to signal shutdowns.
-	donesMu     sync.Mutex // guards dones and shutdownSig
-	dones       []chan os.Signal
-	shutdownSig os.Signal
+	donesMu         sync.Mutex // guards dones, waits, shutdownSig, shutdownTimeout, sigRelay, and ignoredSignals
+	dones           []chan os.Signal
+	waits           []chan ShutdownSignal
+	shutdownSig     *ShutdownSignal
+	shutdownTimeout time.Duration  // requested with ShutdownTimeout
+	sigRelay        *signalRelay   // non-nil while relaying signals to waits
+	ignoredSignals  chan os.Signal // non-nil while ignoring default shutdown signals
 
 	osExit func(code int) // os.Exit override; used for testing only
 }
@@ -314,6 +365,10 @@ type provide struct {
 	// IsSupply is true when the Target constructor was emitted by fx.Supply.
 	IsSupply   bool
 	SupplyType reflect.Type // set only if IsSupply
+
+	// Set if the constructor's outputs should be visible only to the
+	// module it was provided to and that module's children.
+	Private bool
 }
 
 // invoke is a single invocation request to Fx.
@@ -333,6 +388,10 @@ type ErrorHandler interface {
 // ErrorHook registers error handlers that implement error handling functions.
 // They are executed on invoke failures. Passing multiple ErrorHandlers appends
 // the new handlers to the application's existing list.
+//
+// When passed to an fx.Module, the handlers run only for failed invokes of
+// that module and its children, before the handlers of the enclosing modules
+// and the application.
 func ErrorHook(funcs ...ErrorHandler) Option {
 	return errorHookOption(funcs)
 }
@@ -340,7 +399,7 @@ func ErrorHook(funcs ...ErrorHandler) Option {
 type errorHookOption []ErrorHandler
 
 func (eho errorHookOption) apply(m *module) {
-	m.app.errorHooks = append(m.app.errorHooks, eho...)
+	m.errorHooks = append(m.errorHooks, eho...)
 }
 
 func (eho errorHookOption) String() string {
@@ -436,11 +495,15 @@ func New(opts ...Option) *App {
 		// user gave us. For the last case, however, we need to fall
 		// back to what was provided to fx.Logger if fx.WithLogger
 		// fails.
-		log:          logger,
-		clock:        fxclock.System,
-		startTimeout: DefaultTimeout,
-		stopTimeout:  DefaultTimeout,
+		log:             logger,
+		clock:           fxclock.System,
+		startTimeout:    DefaultTimeout,
+		stopTimeout:     DefaultTimeout,
+		readiness:       newReadiness(),
+		shutdownSignals: _defaultShutdownSignals,
+		signalHandlers:  new(signalHandlers),
 	}
+	app.runner = newRunner(app.shutdowner())
 	app.root = &module{app: app}
 	app.modules = append(app.modules, app.root)
 
@@ -457,9 +520,20 @@ func New(opts ...Option) *App {
 	//   the public fx.Hook type.
 	// - appLogger ensures that the lifecycle always logs events to the
 	//   "current" logger associated with the fx.App.
+	// - hookGraph, if set, lets lifecycleWrapper attribute each hook to
+	//   the constructor that appended it.
+	if app.parallelHooks {
+		app.hookGraph = newHookGraph()
+	}
 	app.lifecycle = &lifecycleWrapper{
-		lifecycle.New(appLogger{app}, app.clock),
+		Lifecycle: lifecycle.New(appLogger{app}, app.clock),
+		graph:     app.hookGraph,
 	}
+	app.lifecycle.SetParallel(app.parallelHooks)
+	app.lifecycle.SetSlowHookThreshold(app.slowHookThreshold)
+	// Start the Runner before OnStart hooks so that they can use it, but
+	// only once Start is allowed to proceed.
+	app.lifecycle.SetOnStarting(app.runner.start)
 
 	var (
 		bufferLogger *logBuffer // nil if WithLogger was not used
@@ -498,6 +572,9 @@ func New(opts ...Option) *App {
 		Stack:  frames,
 	})
 	app.root.provide(provide{Target: app.shutdowner, Stack: frames})
+	app.root.provide(provide{Target: app.readinessChecks, Stack: frames})
+	app.root.provide(provide{Target: app.goRunner, Stack: frames})
+	app.root.provide(provide{Target: app.signalHandlerRegistry, Stack: frames})
 	app.root.provide(provide{Target: app.dotGraph, Stack: frames})
 
 	// Run decorators before executing any Invokes -- including the one
@@ -517,17 +594,25 @@ func New(opts ...Option) *App {
 			app.err = multierr.Append(app.err, err)
 			app.log = fallbackLogger
 			bufferLogger.Connect(fallbackLogger)
-			return app
 		}
 	}
 
+	// Modules with loggers of their own are buffering their messages too.
+	// Build their loggers, or flush to their parents' loggers, even if the
+	// provide loop failed so that its errors reach them.
+	app.err = multierr.Append(app.err, app.root.constructCustomLoggers())
+
 	// This error might have come from the provide loop above. We've
-	// already flushed to the custom logger, so we can return.
+	// already flushed to the custom loggers, so we can return.
 	if app.err != nil {
 		return app
 	}
 
-	if err := app.root.executeInvokes(); err != nil {
+	failed, err := app.root.evaluateConditionals()
+	if err == nil {
+		failed, err = app.root.executeInvokes()
+	}
+	if err != nil {
 		app.err = err
 
 		if dig.CanVisualizeError(err) {
@@ -538,7 +623,7 @@ func New(opts ...Option) *App {
 				err:   err,
 			}
 		}
-		errorHandlerList(app.errorHooks).HandleError(err)
+		failed.errorHandlers().HandleError(err)
 	}
 
 	return app
@@ -591,6 +676,11 @@ func (app *App) exit(code int) {
 // configured different timeouts with the StartTimeout or StopTimeout options.
 // It's designed to make typical applications simple to run.
 //
+// If the application was shut down with Shutdowner.Shutdown and the
+// ExitCode option, Run exits the process with that code after a successful
+// Stop. Similarly, a ShutdownTimeout passed to Shutdown replaces StopTimeout
+// as the deadline for that Stop.
+//
 // However, all of Run's functionality is implemented in terms of the exported
 // Start, Done, and Stop methods. Applications with more specialized needs
 // can use those methods directly instead of relying on Run.
@@ -612,16 +702,20 @@ func (app *App) run(done <-chan os.Signal) (exitCode int) {
 	}
 
 	sig := <-done
-	app.log.LogEvent(&fxevent.Stopping{Signal: sig})
+	req, stopTimeout := app.shutdownRequest()
+	app.log.LogEvent(&fxevent.Stopping{Signal: sig, Cause: req.Err})
 
-	stopCtx, cancel := app.clock.WithTimeout(context.Background(), app.StopTimeout())
+	if stopTimeout <= 0 {
+		stopTimeout = app.StopTimeout()
+	}
+	stopCtx, cancel := app.clock.WithTimeout(context.Background(), stopTimeout)
 	defer cancel()
 
 	if err := app.Stop(stopCtx); err != nil {
 		return 1
 	}
 
-	return 0
+	return req.ExitCode
 }
 
 // Err returns any error encountered during New's initialization. See the
@@ -635,6 +729,36 @@ func (app *App) Err() error {
 	return app.err
 }
 
+// withContainer calls fn while holding containerMu.
+//
+// Constructors run by the container may use it again, for example by calling
+// a function provided with Lazy. The container allows this on the same
+// goroutine, so if withContainer is already running on the calling goroutine,
+// fn runs without taking the lock again. Other goroutines wait for the lock,
+// so a constructor that waits for another goroutine to use the container
+// never returns.
+func (app *App) withContainer(fn func() error) error {
+	// Only the goroutine holding containerMu can be nested inside
+	// withContainer, so walk the stack only if it's held.
+	if atomic.LoadInt32(&app.containerHeld) == 1 && fxreflect.OnStack(holdingContainer) {
+		return fn()
+	}
+
+	app.containerMu.Lock()
+	atomic.StoreInt32(&app.containerHeld, 1)
+	defer func() {
+		atomic.StoreInt32(&app.containerHeld, 0)
+		app.containerMu.Unlock()
+	}()
+	return holdingContainer(fn)
+}
+
+// holdingContainer calls fn. Its frame marks goroutines that hold
+// containerMu.
+func holdingContainer(fn func() error) error {
+	return fn()
+}
+
 var (
 	_onStartHook = "OnStart"
 	_onStopHook  = "OnStop"
@@ -653,10 +777,16 @@ var (
 // Lifecycle, one at a time and in order. This ensures that each constructor's
 // start hooks aren't executed until all its dependencies' start hooks
 // complete. If any of the start hooks return an error, Start short-circuits,
-// calls Stop, and returns the inciting error.
+// calls Stop, and returns the inciting error. With the ParallelHooks option,
+// hooks of constructors that don't depend on each other run concurrently.
+//
+// Once all OnStart hooks succeed, Start waits for the checks registered with
+// the application's Readiness to pass. If any of them fail, Start calls Stop
+// and returns the error as well.
 //
 // Note that Start short-circuits immediately if the New constructor
-// encountered any errors in application initialization.
+// encountered any errors in application initialization. If the application
+// isn't stopped, Start returns a *StateError without running any hooks.
 func (app *App) Start(ctx context.Context) (err error) {
 	defer func() {
 		app.log.LogEvent(&fxevent.Started{Err: err})
@@ -667,6 +797,14 @@ func (app *App) Start(ctx context.Context) (err error) {
 		return app.err
 	}
 
+	defer func() {
+		// No hooks ran if Start wasn't allowed, and the records are
+		// those of the previous run.
+		if !isStateError(err) {
+			app.logHookRuntimes(_onStartHook, app.lifecycle.startHookRecords)
+		}
+	}()
+
 	return withTimeout(ctx, &withTimeoutParams{
 		hook:      _onStartHook,
 		callback:  app.start,
@@ -677,21 +815,41 @@ func (app *App) Start(ctx context.Context) (err error) {
 
 func (app *App) start(ctx context.Context) error {
 	if err := app.lifecycle.Start(ctx); err != nil {
-		// Start failed, rolling back.
-		app.log.LogEvent(&fxevent.RollingBack{StartErr: err})
+		// The application is already running; nothing to roll back.
+		var terr *lifecycle.TransitionError
+		if errors.As(err, &terr) {
+			return asStateError(err)
+		}
 
-		stopErr := app.lifecycle.Stop(ctx)
-		app.log.LogEvent(&fxevent.RolledBack{Err: stopErr})
+		return app.rollback(ctx, err)
+	}
 
-		if stopErr != nil {
-			return multierr.Append(err, stopErr)
-		}
+	// Goroutines registered by constructors may depend on anything
+	// started by OnStart hooks.
+	app.runner.launchPending()
 
-		return err
+	if err := app.readiness.wait(ctx); err != nil {
+		return app.rollback(ctx, err)
 	}
+	app.log.LogEvent(&fxevent.Ready{})
+	app.signalHandlers.start()
 	return nil
 }
 
+// rollback stops an application that failed to start with startErr.
+func (app *App) rollback(ctx context.Context, startErr error) error {
+	app.log.LogEvent(&fxevent.RollingBack{StartErr: startErr})
+
+	stopErr := app.stop(ctx)
+	app.log.LogEvent(&fxevent.RolledBack{Err: stopErr})
+
+	if stopErr != nil {
+		return multierr.Append(startErr, stopErr)
+	}
+
+	return startErr
+}
+
 // Stop gracefully stops the application. It executes any registered OnStop
 // hooks in reverse order, so that each constructor's stop hooks are called
 // before its dependencies' stop hooks.
@@ -699,23 +857,58 @@ func (app *App) start(ctx context.Context) error {
 // If the application didn't start cleanly, only hooks whose OnStart phase was
 // called are executed. However, all those hooks are executed, even if some
 // fail.
+//
+// Before running OnStop hooks, Stop cancels the goroutines started with the
+// application's Runner and waits for them to return.
+//
+// If the application isn't starting or started, Stop returns a *StateError
+// without running any hooks.
 func (app *App) Stop(ctx context.Context) (err error) {
+	// Don't handle signals while hooks are stopping.
+	app.signalHandlers.stop()
+
 	defer func() {
+		app.stopSignalRelay()
+		app.resetShutdown()
+		app.readiness.reset()
 		app.log.LogEvent(&fxevent.Stopped{Err: err})
 	}()
 
-	return withTimeout(ctx, &withTimeoutParams{
+	defer func() {
+		if !isStateError(err) {
+			app.logHookRuntimes(_onStopHook, app.lifecycle.stopHookRecords)
+		}
+	}()
+
+	// Goroutines started with the Runner are waited on before the OnStop
+	// hooks run, and report their own stop deadline error.
+	runErr := app.runner.stop(ctx)
+	return multierr.Append(runErr, withTimeout(ctx, &withTimeoutParams{
 		hook:      _onStopHook,
-		callback:  app.lifecycle.Stop,
+		callback:  app.stopLifecycle,
 		lifecycle: app.lifecycle,
 		log:       app.log,
-	})
+	}))
+}
+
+// stop waits for the goroutines of the application's Runner to return, and
+// then runs the OnStop hooks.
+func (app *App) stop(ctx context.Context) error {
+	runErr := app.runner.stop(ctx)
+	return multierr.Append(runErr, app.stopLifecycle(ctx))
+}
+
+// stopLifecycle runs the OnStop hooks, reporting a StateError if the
+// application can't be stopped.
+func (app *App) stopLifecycle(ctx context.Context) error {
+	return asStateError(app.lifecycle.Stop(ctx))
 }
 
 // Done returns a channel of signals to block on after starting the
 // application. Applications listen for the SIGINT and SIGTERM signals; during
 // development, users can send the application SIGTERM by pressing Ctrl-C in
-// the same terminal as the running process.
+// the same terminal as the running process. Use the ShutdownSignals option
+// to listen for a different set of signals.
 //
 // Alternatively, a signal can be broadcast to all done channels manually by
 // using the Shutdown functionality (see the Shutdowner documentation for details).
@@ -728,15 +921,60 @@ func (app *App) Done() <-chan os.Signal {
 	// send it and return. If not, wait for user to send a termination
 	// signal.
 	if app.shutdownSig != nil {
-		c <- app.shutdownSig
+		c <- app.shutdownSig.Signal
 		return c
 	}
 
-	signal.Notify(c, os.Interrupt, _sigINT, _sigTERM)
+	app.notifyShutdownSignals(c)
 	app.dones = append(app.dones, c)
 	return c
 }
 
+// Ready returns a channel that is closed once the application has started
+// and all checks registered with Readiness have passed. See Readiness for
+// details.
+//
+// Stop replaces the channel, so that a restarted application can report
+// readiness again.
+func (app *App) Ready() <-chan struct{} {
+	return app.readiness.readyChan()
+}
+
+// Wait returns a channel of ShutdownSignal to block on after starting the
+// application. It is similar to Done, but in addition to the signal that
+// caused the shutdown, the ShutdownSignal carries the exit code requested
+// with the ExitCode option, if any.
+//
+// Like Done, Wait channels receive the signals that shut the application
+// down (SIGINT and SIGTERM unless changed with ShutdownSignals), as well as
+// signals broadcast with Shutdowner. Operating system signals are delivered
+// to Wait channels until the application is stopped with Stop.
+func (app *App) Wait() <-chan ShutdownSignal {
+	c := make(chan ShutdownSignal, 1)
+
+	app.donesMu.Lock()
+	defer app.donesMu.Unlock()
+	// If shutdown signal has been received already
+	// send it and return.
+	if app.shutdownSig != nil {
+		c <- *app.shutdownSig
+		return c
+	}
+
+	app.startSignalRelay()
+	app.waits = append(app.waits, c)
+	return c
+}
+
+// State reports the lifecycle state of the application.
+//
+// Start and Stop fail with a *StateError without running any hooks if the
+// application isn't in a state that allows them: Start requires
+// StateStopped, and Stop requires StateStarting or StateStarted.
+func (app *App) State() State {
+	return State(app.lifecycle.State())
+}
+
 // StartTimeout returns the configured startup timeout. Apps default to using
 // DefaultTimeout, but users can configure this behavior using the
 // StartTimeout option.
@@ -757,6 +995,18 @@ func (app *App) dotGraph() (DotGraph, error) {
 	return DotGraph(b.String()), err
 }
 
+// logHookRuntimes reports the runtimes of the OnStart or OnStop hooks that
+// have finished running, and the total runtime of each module's hooks,
+// slowest first.
+func (app *App) logHookRuntimes(method string, records func() lifecycle.HookRecords) {
+	rs := records()
+	app.log.LogEvent(&fxevent.HookRuntimes{
+		Method:  method,
+		Hooks:   rs.HookRuntimes(),
+		Modules: rs.ModuleRuntimes(),
+	})
+}
+
 type withTimeoutParams struct {
 	log       fxevent.Logger
 	hook      string
@@ -810,9 +1060,9 @@ func withTimeout(ctx context.Context, param *withTimeoutParams) error {
 		r = param.lifecycle.stopHookRecords()
 	}
 	caller := param.lifecycle.runningHookCaller()
-	// TODO: Once this is integrated into fxevent, we can
-	// leave error unchanged and send this to fxevent.Logger, whose
-	// implementation can then determine how the error is presented.
+	// The same records are sent to fxevent.Logger as a HookRuntimes event
+	// at the end of Start and Stop. They're kept in the error as well
+	// because callers may not have access to the logger's output.
 	if len(r) > 0 {
 		sort.Sort(r)
 		return fmt.Errorf("%v hook added by %v failed: %w\n%+v",
@@ -21,10 +21,13 @@
 package fx
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -54,16 +57,161 @@ func TestAppRun(t *testing.T) {
 	wg.Wait()
 
 	assert.Equal(t, []string{
+		"Provided",
+		"Provided",
+		"Provided",
 		"Provided",
 		"Provided",
 		"Provided",
 		"LoggerInitialized",
-		"Started",
+		"LifecycleStateChanged", "LifecycleStateChanged",
+		"Ready",
+		"HookRuntimes", "Started",
 		"Stopping",
-		"Stopped",
+		"LifecycleStateChanged", "LifecycleStateChanged",
+		"HookRuntimes", "Stopped",
 	}, spy.EventTypes())
 }
 
+func TestShutdownExitCode(t *testing.T) {
+	t.Parallel()
+
+	app := New(WithLogger(func() fxevent.Logger { return fxevent.NopLogger }))
+	var s Shutdowner
+	require.NoError(t, app.container.Invoke(func(sd Shutdowner) { s = sd }))
+
+	require.NoError(t, s.Shutdown(ExitCode(2)))
+	req, _ := app.shutdownRequest()
+	assert.Equal(t, 2, req.ExitCode)
+
+	// Options must not persist across Shutdown calls.
+	<-app.Done()
+	require.NoError(t, s.Shutdown())
+	req, _ = app.shutdownRequest()
+	assert.Equal(t, 0, req.ExitCode)
+}
+
+func TestAppRunShutdownOnError(t *testing.T) {
+	t.Parallel()
+
+	sadness := errors.New("great sadness")
+	spy := new(fxlog.Spy)
+	app := New(
+		WithLogger(func() fxevent.Logger { return spy }),
+		Invoke(func(r Runner) {
+			r.Go(func(context.Context) error {
+				return sadness
+			}, ShutdownOnError(ExitCode(3)))
+		}),
+	)
+	require.NoError(t, app.Err())
+
+	assert.Equal(t, 3, app.run(app.Done()))
+
+	stopping := spy.Events().SelectByTypeName("Stopping")
+	require.Equal(t, 1, stopping.Len())
+	assert.ErrorIs(t, stopping[0].(*fxevent.Stopping).Cause, sadness)
+
+	stopped := spy.Events().SelectByTypeName("Stopped")
+	require.Equal(t, 1, stopped.Len())
+	assert.NoError(t, stopped[0].(*fxevent.Stopped).Err,
+		"the error must be reported only as the shutdown cause")
+}
+
+func TestAppRunShutdownOptions(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("health check failed")
+	var remaining time.Duration
+	spy := new(fxlog.Spy)
+	app := New(
+		WithLogger(func() fxevent.Logger { return spy }),
+		Invoke(func(lc Lifecycle, s Shutdowner) {
+			lc.Append(Hook{
+				OnStart: func(context.Context) error {
+					return s.Shutdown(ShutdownTimeout(time.Second), ShutdownCause(cause))
+				},
+				OnStop: func(ctx context.Context) error {
+					deadline, ok := ctx.Deadline()
+					require.True(t, ok, "stop context must have a deadline")
+					remaining = time.Until(deadline)
+					return nil
+				},
+			})
+		}),
+	)
+	require.NoError(t, app.Err())
+
+	assert.Equal(t, 0, app.run(app.Done()))
+	assert.LessOrEqual(t, remaining, time.Second, "ShutdownTimeout must replace StopTimeout")
+
+	stopping := spy.Events().SelectByTypeName("Stopping")
+	require.Equal(t, 1, stopping.Len())
+	assert.Equal(t, cause, stopping[0].(*fxevent.Stopping).Cause)
+}
+
+func TestWaitRelaysSignals(t *testing.T) {
+	t.Parallel()
+
+	app := New(WithLogger(func() fxevent.Logger { return fxevent.NopLogger }))
+	require.NoError(t, app.Start(context.Background()))
+
+	wait := app.Wait()
+	app.sigRelay.signals <- _sigINT
+	assert.Equal(t, ShutdownSignal{Signal: _sigINT}, <-wait)
+
+	require.NoError(t, app.Stop(context.Background()))
+	assert.Nil(t, app.sigRelay, "relay must stop with the application")
+}
+
+func TestSignalHandlers(t *testing.T) {
+	t.Parallel()
+
+	handled := make(chan os.Signal, 1)
+	app := New(
+		WithLogger(func() fxevent.Logger { return fxevent.NopLogger }),
+		Invoke(func(sh SignalHandlers) {
+			sh.Append(SignalHandler{
+				Signals:  []os.Signal{_sigINT},
+				OnSignal: func(sig os.Signal) { handled <- sig },
+			})
+			sh.Append(SignalHandler{
+				Signals: []os.Signal{_sigTERM},
+				OnSignal: func(sig os.Signal) {
+					t.Errorf("unexpected signal %v", sig)
+				},
+			})
+		}),
+	)
+	require.NoError(t, app.Err())
+	assert.Nil(t, app.signalHandlers.relay, "signals must not be handled before Start")
+
+	require.NoError(t, app.Start(context.Background()))
+	app.signalHandlers.relay.signals <- _sigINT
+	assert.Equal(t, _sigINT, <-handled)
+
+	require.NoError(t, app.Stop(context.Background()))
+	assert.Nil(t, app.signalHandlers.relay, "relay must stop with the application")
+}
+
+func TestShutdownSignalsEmpty(t *testing.T) {
+	t.Parallel()
+
+	app := New(
+		WithLogger(func() fxevent.Logger { return fxevent.NopLogger }),
+		ShutdownSignals(),
+	)
+	require.NoError(t, app.Err())
+	assert.Empty(t, app.shutdownSignals)
+
+	app.Done()
+	assert.NotNil(t, app.ignoredSignals, "default shutdown signals must be ignored")
+
+	require.NoError(t, app.Start(context.Background()))
+	require.NoError(t, app.Stop(context.Background()))
+	assert.Nil(t, app.ignoredSignals, "signals must not be ignored once stopped")
+}
+
 // TestValidateString verifies private option. Public options are tested in app_test.go.
 func TestValidateString(t *testing.T) {
 	t.Parallel()
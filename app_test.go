@@ -31,6 +31,8 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -109,7 +111,7 @@ func TestNewApp(t *testing.T) {
 			WithLogger(func() fxevent.Logger { return spy }))
 		defer app.RequireStart().RequireStop()
 		require.Equal(t,
-			[]string{"Provided", "Provided", "Provided", "Provided", "LoggerInitialized", "Started"},
+			[]string{"Provided", "Provided", "Provided", "Provided", "Provided", "Provided", "Provided", "LoggerInitialized", "LifecycleStateChanged", "LifecycleStateChanged", "Ready", "HookRuntimes", "Started"},
 			spy.EventTypes())
 
 		assert.Contains(t, spy.Events()[0].(*fxevent.Provided).OutputTypeNames, "struct {}")
@@ -312,7 +314,7 @@ func TestNewApp(t *testing.T) {
 		require.Error(t, err)
 
 		// Example:
-		// fx.Provide(fx.Annotate(go.uber.org/fx_test.TestNewApp.func10.1(), fx.ResultTags(["name:\"foo\""])) from:
+		// fx.Provide(fx.Annotate(go.uber.org/fx_test.TestNewApp.func10.1(), fx.ResultTags(["name:\"foo\""]))) from:
 		//     go.uber.org/fx_test.TestNewApp.func10
 		//         /.../fx/app_test.go:305
 		//     testing.tRunner
@@ -357,7 +359,7 @@ func TestNewApp(t *testing.T) {
 		defer app.RequireStart().RequireStop()
 
 		require.Equal(t,
-			[]string{"Provided", "Provided", "Provided", "Provided", "Decorated", "LoggerInitialized", "Invoking", "Invoked", "Started"},
+			[]string{"Provided", "Provided", "Provided", "Provided", "Provided", "Provided", "Provided", "Decorated", "LoggerInitialized", "Invoking", "Invoked", "LifecycleStateChanged", "LifecycleStateChanged", "Ready", "HookRuntimes", "Started"},
 			spy.EventTypes())
 	})
 
@@ -378,7 +380,7 @@ func TestNewApp(t *testing.T) {
 		defer app.RequireStart().RequireStop()
 
 		require.Equal(t,
-			[]string{"Provided", "Provided", "Provided", "Provided", "Decorated", "Decorated", "LoggerInitialized", "Started"},
+			[]string{"Provided", "Provided", "Provided", "Provided", "Provided", "Provided", "Provided", "Decorated", "Decorated", "LoggerInitialized", "LifecycleStateChanged", "LifecycleStateChanged", "Ready", "HookRuntimes", "Started"},
 			spy.EventTypes())
 	})
 
@@ -444,7 +446,7 @@ func TestWithLogger(t *testing.T) {
 		)
 
 		assert.Equal(t, []string{
-			"Supplied", "Provided", "Provided", "Provided", "LoggerInitialized",
+			"Supplied", "Provided", "Provided", "Provided", "Provided", "Provided", "Provided", "LoggerInitialized",
 		}, spy.EventTypes())
 
 		spy.Reset()
@@ -452,7 +454,10 @@ func TestWithLogger(t *testing.T) {
 
 		require.NoError(t, app.Err())
 
-		assert.Equal(t, []string{"Started", "Stopped"}, spy.EventTypes())
+		assert.Equal(t, []string{
+			"LifecycleStateChanged", "LifecycleStateChanged", "Ready", "HookRuntimes", "Started",
+			"LifecycleStateChanged", "LifecycleStateChanged", "HookRuntimes", "Stopped",
+		}, spy.EventTypes())
 	})
 
 	t.Run("error in Provide shows logs", func(t *testing.T) {
@@ -922,6 +927,51 @@ func TestAppRunTimeout(t *testing.T) {
 	}
 }
 
+func TestAppRunExitCode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ShutdownWithExitCode", func(t *testing.T) {
+		t.Parallel()
+
+		var exitCode int
+		app := fxtest.New(t,
+			WithExit(func(code int) { exitCode = code }),
+			Invoke(func(lc Lifecycle, s Shutdowner) {
+				lc.Append(Hook{
+					OnStart: func(context.Context) error {
+						return s.Shutdown(ExitCode(3))
+					},
+				})
+			}),
+		)
+
+		app.Run()
+		assert.Equal(t, 3, exitCode, "exit code mismatch")
+	})
+
+	t.Run("StopFailureOverridesExitCode", func(t *testing.T) {
+		t.Parallel()
+
+		var exitCode int
+		app := fxtest.New(t,
+			WithExit(func(code int) { exitCode = code }),
+			Invoke(func(lc Lifecycle, s Shutdowner) {
+				lc.Append(Hook{
+					OnStart: func(context.Context) error {
+						return s.Shutdown(ExitCode(3))
+					},
+					OnStop: func(context.Context) error {
+						return errors.New("great sadness")
+					},
+				})
+			}),
+		)
+
+		app.Run()
+		assert.Equal(t, 1, exitCode, "exit code mismatch")
+	})
+}
+
 func TestAppStart(t *testing.T) {
 	t.Parallel()
 
@@ -1090,14 +1140,16 @@ func TestAppStart(t *testing.T) {
 		assert.Contains(t, err.Error(), "OnStart fail")
 
 		assert.Equal(t, []string{
-			"Provided", "Provided", "Provided", "Provided",
+			"Provided", "Provided", "Provided", "Provided", "Provided", "Provided", "Provided",
 			"LoggerInitialized",
 			"Invoking",
 			"Invoked",
+			"LifecycleStateChanged",
 			"OnStartExecuting", "OnStartExecuted",
 			"RollingBack",
+			"LifecycleStateChanged", "LifecycleStateChanged",
 			"RolledBack",
-			"Started",
+			"HookRuntimes", "Started",
 		}, spy.EventTypes())
 	})
 
@@ -1126,16 +1178,19 @@ func TestAppStart(t *testing.T) {
 		assert.Equal(t, []error{errStart2, errStop1}, multierr.Errors(err))
 
 		assert.Equal(t, []string{
-			"Provided", "Provided", "Provided", "Provided",
+			"Provided", "Provided", "Provided", "Provided", "Provided", "Provided", "Provided",
 			"LoggerInitialized",
 			"Invoking",
 			"Invoked",
+			"LifecycleStateChanged",
 			"OnStartExecuting", "OnStartExecuted",
 			"OnStartExecuting", "OnStartExecuted",
 			"RollingBack",
+			"LifecycleStateChanged",
 			"OnStopExecuting", "OnStopExecuted",
+			"LifecycleStateChanged",
 			"RolledBack",
-			"Started",
+			"HookRuntimes", "Started",
 		}, spy.EventTypes())
 	})
 
@@ -1157,7 +1212,7 @@ func TestAppStart(t *testing.T) {
 		//         /.../go/1.13.3/libexec/src/testing/testing.go:909
 		// Failed: can't invoke non-function {} (type struct {})
 		require.Equal(t,
-			[]string{"Provided", "Provided", "Provided", "LoggerInitialized", "Invoking", "Invoked"},
+			[]string{"Provided", "Provided", "Provided", "Provided", "Provided", "Provided", "LoggerInitialized", "Invoking", "Invoked"},
 			spy.EventTypes())
 		failedEvent := spy.Events()[len(spy.EventTypes())-1].(*fxevent.Invoked)
 		assert.Contains(t, failedEvent.Err.Error(), "can't invoke non-function")
@@ -1281,6 +1336,191 @@ func TestAppStart(t *testing.T) {
 		err := app.Start(context.Background()).Error()
 		assert.Contains(t, err, "OnStart hook added by go.uber.org/fx_test.TestAppStart.func10.1 failed: goroutine exited without returning")
 	})
+
+	t.Run("HookTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		mockClock := clock.NewMock()
+		release := make(chan struct{})
+
+		spy := new(fxlog.Spy)
+		app := New(
+			WithLogger(func() fxevent.Logger { return spy }),
+			WithClock(mockClock),
+			Invoke(func(lc Lifecycle) {
+				lc.Append(Hook{
+					OnStart: func(ctx context.Context) error {
+						mockClock.Add(2 * time.Second)
+						<-ctx.Done()
+						<-release
+						return nil
+					},
+					OnStartTimeout: time.Second,
+				})
+			}),
+		)
+
+		err := app.Start(context.Background())
+		close(release)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Regexp(t, `OnStart hook added by go.uber.org/fx_test.TestAppStart.func\d+.\d+ \(.*app_test.go:\d+\) timed out after 1s`, err.Error())
+	})
+}
+
+func TestParallelHooks(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+	type C struct{}
+
+	t.Run("IndependentConstructors", func(t *testing.T) {
+		t.Parallel()
+
+		// Hooks of A and B wait for each other, so they only succeed if
+		// they run concurrently.
+		var wg sync.WaitGroup
+		wg.Add(2)
+		rendezvous := func(context.Context) error {
+			wg.Done()
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-time.After(5 * time.Second):
+				return errors.New("hooks did not run concurrently")
+			}
+		}
+
+		var aStarted, aStopped, cStopped int32
+		app := fxtest.New(t,
+			ParallelHooks(),
+			Provide(
+				func(lc Lifecycle) *A {
+					lc.Append(Hook{
+						OnStart: func(ctx context.Context) error {
+							defer atomic.StoreInt32(&aStarted, 1)
+							return rendezvous(ctx)
+						},
+						OnStop: func(context.Context) error {
+							assert.Equal(t, int32(1), atomic.LoadInt32(&cStopped), "C must stop before A")
+							atomic.StoreInt32(&aStopped, 1)
+							return nil
+						},
+					})
+					return &A{}
+				},
+				func(lc Lifecycle) *B {
+					lc.Append(Hook{OnStart: rendezvous})
+					return &B{}
+				},
+				func(lc Lifecycle, _ *A) *C {
+					lc.Append(Hook{
+						OnStart: func(context.Context) error {
+							assert.Equal(t, int32(1), atomic.LoadInt32(&aStarted), "A must start before C")
+							return nil
+						},
+						OnStop: func(context.Context) error {
+							assert.Equal(t, int32(0), atomic.LoadInt32(&aStopped), "A must stop after C")
+							atomic.StoreInt32(&cStopped, 1)
+							return nil
+						},
+					})
+					return &C{}
+				},
+			),
+			Invoke(func(*B, *C) {}),
+		)
+
+		app.RequireStart().RequireStop()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&aStopped), "A was not stopped")
+	})
+
+	t.Run("AnnotatedHooks", func(t *testing.T) {
+		t.Parallel()
+
+		var started []string
+		var mu sync.Mutex
+		record := func(name string) func() error {
+			return func() error {
+				mu.Lock()
+				defer mu.Unlock()
+				started = append(started, name)
+				return nil
+			}
+		}
+
+		app := fxtest.New(t,
+			ParallelHooks(),
+			Provide(
+				Annotate(
+					func() *A { return &A{} },
+					OnStart(func(context.Context) error { return record("A")() }),
+				),
+				Annotate(
+					func(*A) *B { return &B{} },
+					OnStart(func(context.Context) error { return record("B")() }),
+				),
+			),
+			Invoke(func(*B) {}),
+		)
+
+		app.RequireStart().RequireStop()
+		assert.Equal(t, []string{"A", "B"}, started)
+	})
+
+	t.Run("StartFailureRollsBack", func(t *testing.T) {
+		t.Parallel()
+
+		var stopped int32
+		app := NewForTest(t,
+			ParallelHooks(),
+			Provide(
+				func(lc Lifecycle) *A {
+					lc.Append(Hook{
+						OnStart: func(context.Context) error { return nil },
+						OnStop: func(context.Context) error {
+							atomic.StoreInt32(&stopped, 1)
+							return nil
+						},
+					})
+					return &A{}
+				},
+				func(lc Lifecycle, _ *A) *B {
+					lc.Append(Hook{
+						OnStart: func(context.Context) error {
+							return errors.New("great sadness")
+						},
+						OnStop: func(context.Context) error {
+							assert.Fail(t, "OnStop must not run if OnStart failed")
+							return nil
+						},
+					})
+					return &B{}
+				},
+			),
+			Invoke(func(*B) {}),
+		)
+
+		err := app.Start(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&stopped), "A must be rolled back")
+	})
+
+	t.Run("ModuleNotAllowed", func(t *testing.T) {
+		t.Parallel()
+
+		app := NewForTest(t, Module("foo", ParallelHooks()))
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fx.ParallelHooks Option should be passed to top-level App")
+	})
 }
 
 func TestAppStop(t *testing.T) {
@@ -1336,6 +1576,348 @@ func TestAppStop(t *testing.T) {
 	})
 }
 
+func TestAppState(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Transitions", func(t *testing.T) {
+		t.Parallel()
+
+		var app *fxtest.App
+		var states []State
+		app = fxtest.New(t,
+			Invoke(func(lc Lifecycle) {
+				lc.Append(Hook{
+					OnStart: func(context.Context) error {
+						states = append(states, app.State())
+						return nil
+					},
+					OnStop: func(context.Context) error {
+						states = append(states, app.State())
+						return nil
+					},
+				})
+			}),
+		)
+
+		assert.Equal(t, StateStopped, app.State())
+		app.RequireStart()
+		assert.Equal(t, StateStarted, app.State())
+		app.RequireStop()
+		assert.Equal(t, StateStopped, app.State())
+		assert.Equal(t, []State{StateStarting, StateStopping}, states)
+	})
+
+	t.Run("StartTwice", func(t *testing.T) {
+		t.Parallel()
+
+		var stopped bool
+		app := fxtest.New(t,
+			Invoke(func(lc Lifecycle) {
+				lc.Append(Hook{
+					OnStop: func(context.Context) error {
+						stopped = true
+						return nil
+					},
+				})
+			}),
+		)
+
+		app.RequireStart()
+		err := app.Start(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot start lifecycle: lifecycle is started")
+		var serr *StateError
+		require.True(t, errors.As(err, &serr), "must be a StateError")
+		assert.Equal(t, "start", serr.Op)
+		assert.Equal(t, StateStarted, serr.State)
+		assert.False(t, stopped, "a second Start must not roll back the running application")
+		assert.Equal(t, StateStarted, app.State())
+		app.RequireStop()
+		assert.True(t, stopped)
+	})
+
+	t.Run("StateErrorsDoNotReportHookRuntimes", func(t *testing.T) {
+		t.Parallel()
+
+		app, spy := NewSpied(Invoke(func(lc Lifecycle) {
+			lc.Append(StartStopHook(func() {}, func() {}))
+		}))
+		require.NoError(t, app.Start(context.Background()))
+		require.Error(t, app.Start(context.Background()))
+		require.NoError(t, app.Stop(context.Background()))
+		require.Error(t, app.Stop(context.Background()))
+
+		assert.Equal(t, 2, spy.Events().SelectByTypeName("HookRuntimes").Len(),
+			"only the successful Start and Stop must report hook runtimes")
+	})
+
+	t.Run("StopBeforeStart", func(t *testing.T) {
+		t.Parallel()
+
+		app := fxtest.New(t)
+		err := app.Stop(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot stop lifecycle: lifecycle is stopped")
+		var serr *StateError
+		require.True(t, errors.As(err, &serr), "must be a StateError")
+		assert.Equal(t, "stop", serr.Op)
+		assert.Equal(t, StateStopped, serr.State)
+	})
+
+	t.Run("StateString", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "stopped", StateStopped.String())
+		assert.Equal(t, "starting", StateStarting.String())
+		assert.Equal(t, "started", StateStarted.String())
+		assert.Equal(t, "stopping", StateStopping.String())
+	})
+}
+
+func TestHookRuntimes(t *testing.T) {
+	t.Parallel()
+
+	mockClock := clock.NewMock()
+	hook := func(d time.Duration) Hook {
+		advance := func(context.Context) error {
+			mockClock.Add(d)
+			return nil
+		}
+		return Hook{OnStart: advance, OnStop: advance}
+	}
+
+	spy := new(fxlog.Spy)
+	app := New(
+		WithLogger(func() fxevent.Logger { return spy }),
+		WithClock(mockClock),
+		SlowHookThreshold(150*time.Millisecond),
+		Invoke(func(lc Lifecycle) {
+			lc.Append(hook(100 * time.Millisecond))
+			lc.Append(hook(300 * time.Millisecond))
+			lc.Append(hook(200 * time.Millisecond))
+		}),
+	)
+	require.NoError(t, app.Start(context.Background()))
+	require.NoError(t, app.Stop(context.Background()))
+
+	reports := spy.Events().SelectByTypeName("HookRuntimes")
+	require.Equal(t, 2, reports.Len())
+	for i, method := range []string{"OnStart", "OnStop"} {
+		report := reports[i].(*fxevent.HookRuntimes)
+		assert.Equal(t, method, report.Method)
+
+		var runtimes []time.Duration
+		for _, h := range report.Hooks {
+			assert.Equal(t, "go.uber.org/fx_test.TestHookRuntimes.func3", h.CallerName)
+			runtimes = append(runtimes, h.Runtime)
+		}
+		assert.Equal(t, []time.Duration{
+			300 * time.Millisecond,
+			200 * time.Millisecond,
+			100 * time.Millisecond,
+		}, runtimes, "%v hooks must be sorted by runtime", method)
+	}
+
+	var slow []string
+	for _, e := range spy.Events().SelectByTypeName("SlowHook") {
+		e := e.(*fxevent.SlowHook)
+		assert.Equal(t, 150*time.Millisecond, e.Threshold)
+		slow = append(slow, fmt.Sprintf("%v %v", e.Method, e.Runtime))
+	}
+	assert.Equal(t, []string{
+		"OnStart 300ms",
+		"OnStart 200ms",
+		"OnStop 200ms",
+		"OnStop 300ms",
+	}, slow)
+}
+
+func TestReadiness(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ReadyAfterChecksPass", func(t *testing.T) {
+		t.Parallel()
+
+		var started bool
+		listening := make(chan struct{})
+		spy := new(fxlog.Spy)
+		app := fxtest.New(t,
+			WithLogger(func() fxevent.Logger { return spy }),
+			Invoke(func(lc Lifecycle, r Readiness) {
+				lc.Append(Hook{
+					OnStart: func(context.Context) error {
+						started = true
+						go close(listening)
+						return nil
+					},
+				})
+				r.Append(func(ctx context.Context) error {
+					assert.True(t, started, "readiness checks must run after OnStart hooks")
+					select {
+					case <-listening:
+						return nil
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				})
+			}),
+		)
+
+		select {
+		case <-app.Ready():
+			t.Fatal("application must not be ready before it starts")
+		default:
+		}
+
+		app.RequireStart()
+		defer app.RequireStop()
+
+		select {
+		case <-app.Ready():
+		default:
+			t.Fatal("application must be ready once Start returns")
+		}
+		assert.Equal(t, 1, spy.Events().SelectByTypeName("Ready").Len())
+	})
+
+	t.Run("ChecksRunConcurrently", func(t *testing.T) {
+		t.Parallel()
+
+		// Each check waits for the other one, so they only pass if they
+		// run concurrently.
+		a, b := make(chan struct{}), make(chan struct{})
+		app := fxtest.New(t,
+			Invoke(func(r Readiness) {
+				r.Append(func(ctx context.Context) error {
+					close(a)
+					<-b
+					return nil
+				})
+				r.Append(func(ctx context.Context) error {
+					close(b)
+					<-a
+					return nil
+				})
+			}),
+		)
+
+		app.RequireStart().RequireStop()
+	})
+
+	t.Run("FailedCheckRollsBack", func(t *testing.T) {
+		t.Parallel()
+
+		var stopped bool
+		spy := new(fxlog.Spy)
+		app := New(
+			WithLogger(func() fxevent.Logger { return spy }),
+			Invoke(func(lc Lifecycle, r Readiness) {
+				lc.Append(Hook{
+					OnStop: func(context.Context) error {
+						stopped = true
+						return nil
+					},
+				})
+				r.Append(func(context.Context) error {
+					return errors.New("great sadness")
+				})
+				r.Append(func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+			}),
+		)
+		require.NoError(t, app.Err())
+
+		err := app.Start(context.Background())
+		require.Error(t, err)
+		assert.Regexp(t, `readiness check go.uber.org/fx_test.TestReadiness.func\d+.\d+.\d+\(\) failed: great sadness`, err.Error())
+		assert.NotContains(t, err.Error(), "context canceled",
+			"checks canceled after the first failure must not be reported")
+		assert.True(t, stopped, "application must be rolled back")
+		assert.Equal(t, StateStopped, app.State())
+		assert.Equal(t, 0, spy.Events().SelectByTypeName("Ready").Len())
+
+		select {
+		case <-app.Ready():
+			t.Fatal("application must not be ready")
+		default:
+		}
+	})
+}
+
+func TestAppRestart(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RunsHooksAgain", func(t *testing.T) {
+		t.Parallel()
+
+		var starts, stops, checks int
+		app := fxtest.New(t,
+			Invoke(func(lc Lifecycle, r Readiness) {
+				lc.Append(Hook{
+					OnStart: func(context.Context) error {
+						starts++
+						return nil
+					},
+					OnStop: func(context.Context) error {
+						stops++
+						return nil
+					},
+				})
+				r.Append(func(context.Context) error {
+					checks++
+					return nil
+				})
+			}),
+		)
+
+		for i := 1; i <= 2; i++ {
+			app.RequireStart()
+			assert.Equal(t, StateStarted, app.State())
+			<-app.Ready()
+
+			app.RequireStop()
+			assert.Equal(t, StateStopped, app.State())
+			select {
+			case <-app.Ready():
+				t.Fatal("stopped application must not be ready")
+			default:
+			}
+
+			assert.Equal(t, i, starts)
+			assert.Equal(t, i, stops)
+			assert.Equal(t, i, checks)
+		}
+	})
+
+	t.Run("WaitsForNewShutdown", func(t *testing.T) {
+		t.Parallel()
+
+		var s Shutdowner
+		app := fxtest.New(t, Populate(&s))
+
+		app.RequireStart()
+		require.NoError(t, s.Shutdown(ExitCode(3)))
+		assert.Equal(t, 3, (<-app.Wait()).ExitCode)
+		app.RequireStop()
+
+		app.RequireStart()
+		defer app.RequireStop()
+
+		select {
+		case sig := <-app.Wait():
+			t.Fatalf("restarted application received stale signal %v", sig)
+		case <-app.Done():
+			t.Fatal("restarted application received stale signal")
+		default:
+		}
+
+		require.NoError(t, s.Shutdown(ExitCode(4)))
+		assert.Equal(t, 4, (<-app.Wait()).ExitCode)
+	})
+}
+
 func TestValidateApp(t *testing.T) {
 	t.Parallel()
 
@@ -1440,6 +2022,21 @@ func TestDone(t *testing.T) {
 	}
 }
 
+func TestWait(t *testing.T) {
+	t.Parallel()
+
+	app := fxtest.New(t)
+	defer app.RequireStart().RequireStop()
+
+	wait := app.Wait()
+	require.NotNil(t, wait, "Got a nil channel.")
+	select {
+	case sig := <-wait:
+		t.Fatalf("Got unexpected signal %v from application's Wait channel.", sig)
+	default:
+	}
+}
+
 func TestReplaceLogger(t *testing.T) {
 	t.Parallel()
 
@@ -1447,12 +2044,18 @@ func TestReplaceLogger(t *testing.T) {
 	app := fxtest.New(t, WithLogger(func() fxevent.Logger { return spy }))
 	app.RequireStart().RequireStop()
 	assert.Equal(t, []string{
+		"Provided",
+		"Provided",
+		"Provided",
 		"Provided",
 		"Provided",
 		"Provided",
 		"LoggerInitialized",
-		"Started",
-		"Stopped",
+		"LifecycleStateChanged", "LifecycleStateChanged",
+		"Ready",
+		"HookRuntimes", "Started",
+		"LifecycleStateChanged", "LifecycleStateChanged",
+		"HookRuntimes", "Stopped",
 	}, spy.EventTypes())
 }
 
@@ -1525,14 +2128,22 @@ func TestCustomLoggerWithLifecycle(t *testing.T) {
 	require.NoError(t, app.Stop(context.Background()))
 
 	assert.Equal(t, []string{
+		"Provided",
+		"Provided",
+		"Provided",
 		"Provided",
 		"Provided",
 		"Provided",
 		"LoggerInitialized",
+		"LifecycleStateChanged",
 		"OnStartExecuting", "OnStartExecuted",
-		"Started",
+		"LifecycleStateChanged",
+		"Ready",
+		"HookRuntimes", "Started",
+		"LifecycleStateChanged",
 		"OnStopExecuting", "OnStopExecuted",
-		"Stopped",
+		"LifecycleStateChanged",
+		"HookRuntimes", "Stopped",
 	}, spy.EventTypes())
 }
 
@@ -1677,6 +2288,16 @@ func TestOptionString(t *testing.T) {
 			give: Provide(bytes.NewReader),
 			want: "fx.Provide(bytes.NewReader())",
 		},
+		{
+			desc: "Provide/Private",
+			give: Provide(bytes.NewReader, Private),
+			want: "fx.Provide(bytes.NewReader(), fx.Private)",
+		},
+		{
+			desc: "Provide/Annotate/As",
+			give: Provide(Annotate(bytes.NewBuffer, As(Self()), As(new(io.Writer)))),
+			want: "fx.Provide(fx.Annotate(bytes.NewBuffer(), fx.As(fx.Self()), fx.As(io.Writer)))",
+		},
 		{
 			desc: "Invoked",
 			give: Invoke(func(c io.Closer) error {
@@ -1760,6 +2381,41 @@ func TestOptionString(t *testing.T) {
 			give: Replace(bytes.NewReader(nil)),
 			want: "fx.Replace(*bytes.Reader)",
 		},
+		{
+			desc: "Decorate/AtRoot",
+			give: Decorate(bytes.NewBufferString, AtRoot),
+			want: "fx.Decorate(bytes.NewBufferString(), fx.AtRoot)",
+		},
+		{
+			desc: "Replace/AtRoot",
+			give: Replace(bytes.NewReader(nil), AtRoot),
+			want: "fx.Replace(*bytes.Reader, fx.AtRoot)",
+		},
+		{
+			desc: "If",
+			give: If(returnTrue, Invoke(bytes.NewReader)),
+			want: "fx.If(go.uber.org/fx_test.returnTrue(), [fx.Invoke(bytes.NewReader())])",
+		},
+		{
+			desc: "Unless",
+			give: Unless(returnTrue),
+			want: "fx.Unless(go.uber.org/fx_test.returnTrue(), [])",
+		},
+		{
+			desc: "SlowHookThreshold",
+			give: SlowHookThreshold(time.Second),
+			want: "fx.SlowHookThreshold(1s)",
+		},
+		{
+			desc: "ShutdownSignals",
+			give: ShutdownSignals(os.Interrupt, os.Kill),
+			want: "fx.ShutdownSignals(interrupt, killed)",
+		},
+		{
+			desc: "ParallelHooks",
+			give: ParallelHooks(),
+			want: "fx.ParallelHooks()",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1792,6 +2448,8 @@ func (l testLogger) String() string {
 	return l.t.Name()
 }
 
+func returnTrue() bool { return true }
+
 type testErrorHandler struct{ t *testing.T }
 
 func (h testErrorHandler) HandleError(err error) {
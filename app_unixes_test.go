@@ -0,0 +1,144 @@
+// Copyright (c) 2020-2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package fx_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"golang.org/x/sys/unix"
+)
+
+func TestShutdownSignals(t *testing.T) {
+	t.Parallel()
+
+	// No other test listens for SIGUSR2, so it reaches only this
+	// application.
+	app := fxtest.New(t, fx.ShutdownSignals(unix.SIGUSR2))
+	done := app.Done()
+	app.RequireStart()
+	defer app.RequireStop()
+
+	require.NoError(t, unix.Kill(os.Getpid(), unix.SIGUSR2))
+	assert.Equal(t, unix.SIGUSR2, <-done)
+}
+
+func TestSignalHandlersReceiveSignals(t *testing.T) {
+	t.Parallel()
+
+	// No other test listens for SIGUSR1, so it reaches only this
+	// application.
+	handled := make(chan os.Signal, 1)
+	app := fxtest.New(t,
+		fx.Invoke(func(sh fx.SignalHandlers) {
+			sh.Append(fx.SignalHandler{
+				Signals:  []os.Signal{unix.SIGUSR1},
+				OnSignal: func(sig os.Signal) { handled <- sig },
+			})
+		}),
+	)
+	done := app.Done()
+	app.RequireStart()
+	defer app.RequireStop()
+
+	require.NoError(t, unix.Kill(os.Getpid(), unix.SIGUSR1))
+	assert.Equal(t, unix.SIGUSR1, <-handled)
+
+	select {
+	case sig := <-done:
+		t.Fatalf("handled signal must not shut the application down, got %v", sig)
+	default:
+	}
+}
+
+func TestShutdownSignalsIgnoreDroppedDefaults(t *testing.T) {
+	t.Parallel()
+
+	// SIGINT would reach every application waiting on a signal in this
+	// process, so send it to a separate one.
+	testExe, err := os.Executable()
+	require.NoError(t, err, "determine test executable")
+	cmd := exec.Command(testExe, "-test.run", "TestShutdownSignalsMinimalApp")
+	cmd.Env = append(os.Environ(), "FX_TEST_FAKE=1")
+
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err, "create stdout")
+	stderr, err := cmd.StderrPipe()
+	require.NoError(t, err, "create stderr")
+	require.NoError(t, cmd.Start())
+
+	// Block until the child is ready by waiting for the "ready" text
+	// printed to stderr.
+	_, err = stderr.Read(make([]byte, 1024))
+	require.NoError(t, err)
+
+	require.NoError(t, cmd.Process.Signal(unix.SIGINT), "SIGINT child process")
+	require.NoError(t, cmd.Process.Signal(unix.SIGTERM), "SIGTERM child process")
+
+	output, err := io.ReadAll(stdout)
+	require.NoError(t, err)
+	_, err = io.Copy(io.Discard, stderr)
+	require.NoError(t, err)
+	require.NoError(t, cmd.Wait(), "SIGINT must not terminate the process")
+
+	assert.Contains(t, string(output), "ONSTOP",
+		"stdout should include ONSTOP")
+}
+
+func TestShutdownSignalsMinimalApp(t *testing.T) {
+	// This is not a real test.
+	// It defines the behavior of the fake application
+	// that we spawn from TestShutdownSignalsIgnoreDroppedDefaults.
+	if os.Getenv("FX_TEST_FAKE") != "1" {
+		return
+	}
+
+	// An Fx application that shuts down only on SIGTERM, prints "ready"
+	// to stderr once it's waiting for signals, and "ONSTOP" to stdout
+	// when its stop hooks have been invoked.
+	fx.New(
+		fx.NopLogger,
+		fx.ShutdownSignals(unix.SIGTERM),
+		fx.Invoke(func(lc fx.Lifecycle) {
+			lc.Append(fx.Hook{
+				OnStart: func(context.Context) error {
+					fmt.Fprintln(os.Stderr, "ready")
+					return nil
+				},
+				OnStop: func(context.Context) error {
+					fmt.Fprintln(os.Stdout, "ONSTOP")
+					return nil
+				},
+			})
+		}),
+	).Run()
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/fx/internal/fxreflect"
+)
+
+// If applies the given options only if the predicate returns true.
+//
+// The predicate is a function that returns a bool, or a bool and an error.
+// Like functions passed to Invoke, it may accept any number of dependencies,
+// which are built from the constructors available to the application.
+// This lets options depend on values that are themselves provided with Fx,
+// such as configuration.
+//
+//	fx.If(func(cfg *Config) bool {
+//	  return cfg.Tracing.Enabled
+//	},
+//	  fx.Provide(newTracer),
+//	  fx.Invoke(registerTracer),
+//	)
+//
+// If the predicate returns an error, the application fails to build.
+//
+// Predicates run once the application's unconditional constructors and
+// decorators are in place, but before any functions passed to Invoke run.
+// Options applied by a predicate behave as if they had been passed to the
+// enclosing fx.Module, or the top-level App, except that their invokes run
+// after those of the enclosing module. The application can't depend on them
+// before the predicate has run, so predicates can't depend on values provided
+// by other conditional options of the same module. Decorators applied
+// conditionally have no effect on values that were already built to evaluate
+// a predicate.
+//
+// Options that may only be passed to the top-level App, such as StartTimeout,
+// can't be used inside If.
+func If(predicate interface{}, opts ...Option) Option {
+	return conditionalOption{
+		Predicate: predicate,
+		Options:   opts,
+		Stack:     fxreflect.CallerStack(1, 0),
+	}
+}
+
+// Unless applies the given options only if the predicate returns false. It
+// is otherwise identical to If.
+//
+//	fx.Unless(func(cfg *Config) bool {
+//	  return cfg.Cache.Disabled
+//	},
+//	  fx.Provide(newCache),
+//	)
+func Unless(predicate interface{}, opts ...Option) Option {
+	return conditionalOption{
+		Predicate: predicate,
+		Options:   opts,
+		Negate:    true,
+		Stack:     fxreflect.CallerStack(1, 0),
+	}
+}
+
+type conditionalOption struct {
+	Predicate interface{}
+	Options   []Option
+	Negate    bool // set for Unless
+	Stack     fxreflect.Stack
+}
+
+func (o conditionalOption) apply(mod *module) {
+	mod.conditionals = append(mod.conditionals, o)
+}
+
+func (o conditionalOption) name() string {
+	if o.Negate {
+		return "fx.Unless"
+	}
+	return "fx.If"
+}
+
+func (o conditionalOption) String() string {
+	return fmt.Sprintf("%v(%v, %v)", o.name(), fxreflect.FuncName(o.Predicate), o.Options)
+}
+
+// predicate adapts the Predicate into a function that can be passed to
+// Invoke, and that reports whether the options should be applied through
+// the returned pointer.
+func (o conditionalOption) predicate() (fn interface{}, apply *bool, err error) {
+	ft := reflect.TypeOf(o.Predicate)
+	if ft == nil || ft.Kind() != reflect.Func {
+		return nil, nil, fmt.Errorf("predicate must be a function, got %T", o.Predicate)
+	}
+
+	switch {
+	case ft.NumOut() == 1 && ft.Out(0).Kind() == reflect.Bool:
+	case ft.NumOut() == 2 && ft.Out(0).Kind() == reflect.Bool && ft.Out(1) == _typeOfError:
+	default:
+		return nil, nil, fmt.Errorf("predicate must return bool or (bool, error), got %v", ft)
+	}
+
+	ins := make([]reflect.Type, ft.NumIn())
+	for i := range ins {
+		ins[i] = ft.In(i)
+	}
+
+	apply = new(bool)
+	fv := reflect.ValueOf(o.Predicate)
+	wrapperType := reflect.FuncOf(ins, []reflect.Type{_typeOfError}, ft.IsVariadic())
+	wrapper := reflect.MakeFunc(wrapperType, func(args []reflect.Value) []reflect.Value {
+		var results []reflect.Value
+		if ft.IsVariadic() {
+			results = fv.CallSlice(args)
+		} else {
+			results = fv.Call(args)
+		}
+
+		*apply = results[0].Bool() != o.Negate
+		if len(results) == 2 {
+			return results[1:]
+		}
+		return []reflect.Value{_nilError}
+	})
+	return wrapper.Interface(), apply, nil
+}
+
+// evaluateConditionals runs the predicates of the conditional options of
+// this module and its children, and applies the options of those that pass.
+// If something fails, it returns the module the failure belongs to with the
+// error.
+func (m *module) evaluateConditionals() (failed *module, err error) {
+	for _, c := range m.conditionals {
+		mod, err := m.evaluateConditional(c)
+		if err != nil {
+			return m, err
+		}
+		if mod != nil {
+			m.modules = append(m.modules, mod)
+		}
+	}
+
+	for _, mod := range m.modules {
+		if failed, err := mod.evaluateConditionals(); err != nil {
+			return failed, err
+		}
+	}
+	return nil, nil
+}
+
+// evaluateConditional runs the predicate of c in this module. If it passes,
+// evaluateConditional applies c's options to a new module that shares this
+// module's Scope, and returns it.
+func (m *module) evaluateConditional(c conditionalOption) (_ *module, err error) {
+	fnName := fxreflect.FuncName(c.Predicate)
+	m.logger().LogEvent(&fxevent.Invoking{
+		FunctionName: fnName,
+		ModuleName:   m.name,
+	})
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("%v(%v) from:\n%+vFailed: %v", c.name(), fnName, c.Stack, err)
+		}
+		m.logger().LogEvent(&fxevent.Invoked{
+			FunctionName: fnName,
+			ModuleName:   m.name,
+			Err:          err,
+			Trace:        fmt.Sprintf("%+v", c.Stack), // format stack trace as multi-line
+		})
+	}()
+
+	fn, apply, err := c.predicate()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.scope.Invoke(fn); err != nil {
+		return nil, err
+	}
+	if !*apply {
+		return nil, nil
+	}
+
+	mod := &module{
+		name:        m.name,
+		parent:      m,
+		app:         m.app,
+		sharesScope: true,
+	}
+	for _, opt := range c.Options {
+		opt.apply(mod)
+	}
+	if err := m.app.err; err != nil {
+		return nil, err
+	}
+
+	mod.build(m.app, m.app.container)
+	mod.provideAll()
+	if err := m.app.err; err != nil {
+		return nil, err
+	}
+	if err := mod.decorate(); err != nil {
+		return nil, err
+	}
+	if err := mod.constructCustomLoggers(); err != nil {
+		return nil, err
+	}
+	return mod, nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestConditionalSuccess(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Enabled bool
+	}
+	type Feature struct{}
+
+	t.Run("predicate reads from the graph", func(t *testing.T) {
+		t.Parallel()
+
+		for _, enabled := range []bool{true, false} {
+			var called bool
+			app := fxtest.New(t,
+				fx.Provide(func() *Config { return &Config{Enabled: enabled} }),
+				fx.If(func(cfg *Config) bool { return cfg.Enabled },
+					fx.Provide(func() *Feature { return &Feature{} }),
+					fx.Invoke(func(*Feature) { called = true }),
+				),
+			)
+			app.RequireStart().RequireStop()
+			assert.Equal(t, enabled, called)
+		}
+	})
+
+	t.Run("unless", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		app := fxtest.New(t,
+			fx.Supply(&Config{Enabled: true}),
+			fx.Unless(func(cfg *Config) bool { return cfg.Enabled },
+				fx.Invoke(func() { calls = append(calls, "disabled") }),
+			),
+			fx.Unless(func(cfg *Config) (bool, error) { return !cfg.Enabled, nil },
+				fx.Invoke(func() { calls = append(calls, "enabled") }),
+			),
+		)
+		app.RequireStart().RequireStop()
+		assert.Equal(t, []string{"enabled"}, calls)
+	})
+
+	t.Run("conditional provides are visible to the application", func(t *testing.T) {
+		t.Parallel()
+
+		app := fxtest.New(t,
+			fx.Module("feature",
+				fx.If(func() bool { return true },
+					fx.Provide(func() *Feature { return &Feature{} }),
+				),
+			),
+			fx.Invoke(func(f *Feature) {
+				assert.NotNil(t, f)
+			}),
+		)
+		app.RequireStart().RequireStop()
+	})
+
+	t.Run("conditional decorators are scoped to the module", func(t *testing.T) {
+		t.Parallel()
+
+		type Name string
+
+		app := fxtest.New(t,
+			fx.Supply(Name("root")),
+			fx.Module("child",
+				fx.If(func() bool { return true },
+					fx.Decorate(func(n Name) Name { return n + " decorated" }),
+				),
+				fx.Invoke(func(n Name) {
+					assert.Equal(t, Name("root decorated"), n)
+				}),
+			),
+			fx.Invoke(func(n Name) {
+				assert.Equal(t, Name("root"), n)
+			}),
+		)
+		app.RequireStart().RequireStop()
+	})
+
+	t.Run("nested conditionals", func(t *testing.T) {
+		t.Parallel()
+
+		var called bool
+		app := fxtest.New(t,
+			fx.Provide(func() *Config { return &Config{Enabled: true} }),
+			fx.If(func() bool { return true },
+				fx.Provide(func() *Feature { return &Feature{} }),
+				fx.If(func(cfg *Config, _ *Feature) bool { return cfg.Enabled },
+					fx.Invoke(func() { called = true }),
+				),
+			),
+		)
+		app.RequireStart().RequireStop()
+		assert.True(t, called)
+	})
+}
+
+func TestConditionalFailure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("predicate returns an error", func(t *testing.T) {
+		t.Parallel()
+
+		app := NewForTest(t,
+			fx.If(func() (bool, error) { return false, errors.New("great sadness") },
+				fx.Invoke(func() {
+					assert.Fail(t, "this should not be called")
+				}),
+			),
+		)
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fx.If(")
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("predicate dependency is missing", func(t *testing.T) {
+		t.Parallel()
+
+		type Missing struct{}
+		app := NewForTest(t,
+			fx.Unless(func(*Missing) bool { return false }),
+		)
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fx.Unless(")
+		assert.Contains(t, err.Error(), "missing type: *fx_test.Missing")
+	})
+
+	t.Run("invalid predicates", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc string
+			give interface{}
+			want string
+		}{
+			{
+				desc: "not a function",
+				give: true,
+				want: "predicate must be a function, got bool",
+			},
+			{
+				desc: "wrong results",
+				give: func() string { return "" },
+				want: "predicate must return bool or (bool, error), got func() string",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				app := NewForTest(t, fx.If(tt.give))
+				err := app.Err()
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.want)
+			})
+		}
+	})
+
+	t.Run("top-level options are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		app := NewForTest(t,
+			fx.If(func() bool { return true }, fx.StartTimeout(0)),
+		)
+		require.Error(t, app.Err())
+	})
+}
@@ -169,6 +169,20 @@ import (
 //	    }),
 //	  ),
 //	)
+//
+// Decorations never leak out of their module. Sibling modules and the
+// module's parents keep seeing the original values, even if the decorated
+// module is built from a shared fx.Option.
+//
+// To decorate values for the whole application from inside a module, pass
+// AtRoot alongside the decorators. These decorators are applied as if they had
+// been passed to the top-level fx.New call.
+//
+//	fx.Module("tracing",
+//	  fx.Decorate(func(log *zap.Logger) *zap.Logger {
+//	    return log.With(zap.Bool("traced", true))
+//	  }, fx.AtRoot),
+//	)
 func Decorate(decorators ...interface{}) Option {
 	return decorateOption{
 		Targets: decorators,
@@ -182,10 +196,21 @@ type decorateOption struct {
 }
 
 func (o decorateOption) apply(mod *module) {
+	var atRoot bool
+	targets := make([]interface{}, 0, len(o.Targets))
 	for _, target := range o.Targets {
+		if _, ok := target.(atRootOption); ok {
+			atRoot = true
+			continue
+		}
+		targets = append(targets, target)
+	}
+
+	for _, target := range targets {
 		mod.decorators = append(mod.decorators, decorator{
 			Target: target,
 			Stack:  o.Stack,
+			AtRoot: atRoot,
 		})
 	}
 }
@@ -193,6 +218,10 @@ func (o decorateOption) apply(mod *module) {
 func (o decorateOption) String() string {
 	items := make([]string, len(o.Targets))
 	for i, f := range o.Targets {
+		if _, ok := f.(atRootOption); ok {
+			items[i] = "fx.AtRoot"
+			continue
+		}
 		items[i] = fxreflect.FuncName(f)
 	}
 	return fmt.Sprintf("fx.Decorate(%s)", strings.Join(items, ", "))
@@ -208,23 +237,43 @@ type decorator struct {
 
 	// Whether this decorator was specified via fx.Replace
 	IsReplace bool
+
+	// Whether this decorator applies to the whole application rather than
+	// the module it was specified in.
+	AtRoot bool
 }
 
+// AtRoot may be passed to Decorate or Replace alongside decorators or values
+// to apply them to the whole application, as if they had been passed to the
+// top-level fx.New call, even when they're specified inside an fx.Module.
+//
+// AtRoot has no effect on decorators specified at the top level.
+var AtRoot = atRootOption{}
+
+type atRootOption struct{}
+
 func runDecorator(c container, d decorator, opts ...dig.DecorateOption) (err error) {
 	decorator := d.Target
-	defer func() {
-		if err != nil {
-			err = fmt.Errorf("fx.Decorate(%v) from:\n%+vFailed: %v", decorator, d.Stack, err)
-		}
-	}()
+	option := "fx.Decorate"
+	if d.IsReplace {
+		option = "fx.Replace"
+	}
 
 	switch decorator := decorator.(type) {
+	case annotationError:
+		return decorator.report(option, d.Stack)
 	case annotated:
-		if dcor, derr := decorator.Build(); derr == nil {
-			err = c.Decorate(dcor, opts...)
+		var dcor interface{}
+		dcor, err = decorator.build(option, d.Stack)
+		if err != nil {
+			return err
 		}
+		err = c.Decorate(dcor, opts...)
 	default:
 		err = c.Decorate(decorator, opts...)
 	}
-	return
+	if err != nil {
+		err = fmt.Errorf("%v(%v) from:\n%+vFailed: %v", option, decorator, d.Stack, err)
+	}
+	return err
 }
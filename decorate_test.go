@@ -313,6 +313,95 @@ func TestDecorateSuccess(t *testing.T) {
 		)
 		defer app.RequireStart().RequireStop()
 	})
+
+	t.Run("decoration does not leak to sibling or parent modules", func(t *testing.T) {
+		type Logger struct {
+			Name string
+		}
+
+		shared := fx.Module("shared",
+			fx.Invoke(func(l *Logger) {
+				assert.Equal(t, "root", l.Name)
+			}),
+		)
+
+		app := fxtest.New(t,
+			fx.Provide(func() *Logger { return &Logger{Name: "root"} }),
+			fx.Module("a",
+				fx.Decorate(func(l *Logger) *Logger {
+					return &Logger{Name: l.Name + " a"}
+				}),
+				fx.Invoke(func(l *Logger) {
+					assert.Equal(t, "root a", l.Name)
+				}),
+				fx.Module("grandchild",
+					fx.Invoke(func(l *Logger) {
+						assert.Equal(t, "root a", l.Name)
+					}),
+				),
+			),
+			fx.Module("b",
+				fx.Invoke(func(l *Logger) {
+					assert.Equal(t, "root", l.Name)
+				}),
+			),
+			shared,
+			fx.Invoke(func(l *Logger) {
+				assert.Equal(t, "root", l.Name)
+			}),
+		)
+		defer app.RequireStart().RequireStop()
+	})
+
+	t.Run("decorate at root from a module", func(t *testing.T) {
+		type Logger struct {
+			Name string
+		}
+
+		var names []string
+		app := fxtest.New(t,
+			fx.Provide(func() *Logger { return &Logger{Name: "root"} }),
+			fx.Module("a",
+				fx.Decorate(func(l *Logger) *Logger {
+					return &Logger{Name: l.Name + " decorated"}
+				}, fx.AtRoot),
+			),
+			fx.Module("b",
+				fx.Invoke(func(l *Logger) {
+					names = append(names, l.Name)
+				}),
+			),
+			fx.Invoke(func(l *Logger) {
+				names = append(names, l.Name)
+			}),
+		)
+		defer app.RequireStart().RequireStop()
+		assert.Equal(t, []string{"root decorated", "root decorated"}, names)
+	})
+
+	t.Run("module decorator chains with a decorator at root", func(t *testing.T) {
+		type Logger struct {
+			Name string
+		}
+
+		app := fxtest.New(t,
+			fx.Provide(func() *Logger { return &Logger{Name: "root"} }),
+			fx.Module("a",
+				fx.Decorate(func(l *Logger) *Logger {
+					return &Logger{Name: l.Name + " global"}
+				}, fx.AtRoot),
+			),
+			fx.Module("b",
+				fx.Decorate(func(l *Logger) *Logger {
+					return &Logger{Name: l.Name + " b"}
+				}),
+				fx.Invoke(func(l *Logger) {
+					assert.Equal(t, "root global b", l.Name)
+				}),
+			),
+		)
+		defer app.RequireStart().RequireStop()
+	})
 }
 
 func TestDecorateFailure(t *testing.T) {
@@ -365,6 +454,30 @@ func TestDecorateFailure(t *testing.T) {
 		assert.Contains(t, err.Error(), "*fx_test.Logger already decorated")
 	})
 
+	t.Run("decorating a type at root from a module and at the top level errors", func(t *testing.T) {
+		type Logger struct {
+			Name string
+		}
+
+		app := NewForTest(t,
+			fx.Provide(func() *Logger {
+				return &Logger{Name: "root"}
+			}),
+			fx.Decorate(func(l *Logger) *Logger {
+				return &Logger{Name: "dec1 " + l.Name}
+			}),
+			fx.Module("child",
+				fx.Decorate(func(l *Logger) *Logger {
+					return &Logger{Name: "dec2 " + l.Name}
+				}, fx.AtRoot),
+			),
+		)
+
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "*fx_test.Logger already decorated")
+	})
+
 	t.Run("decorating a type more than once in the same Module errors", func(t *testing.T) {
 		type Logger struct {
 			Name string
@@ -387,6 +500,27 @@ func TestDecorateFailure(t *testing.T) {
 		assert.Contains(t, err.Error(), "*fx_test.Logger already decorated")
 	})
 
+	t.Run("annotated decorator for an already decorated type errors", func(t *testing.T) {
+		type Logger struct {
+			Name string
+		}
+
+		app := NewForTest(t,
+			fx.Supply(&Logger{Name: "root"}),
+			fx.Decorate(func(l *Logger) *Logger {
+				return &Logger{Name: "dec1 " + l.Name}
+			}),
+			fx.Decorate(fx.Annotate(func(l *Logger) *Logger {
+				return &Logger{Name: "dec2 " + l.Name}
+			})),
+		)
+
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fx.Decorate(")
+		assert.Contains(t, err.Error(), "*fx_test.Logger already decorated")
+	})
+
 	t.Run("annotated decorator returns an error", func(t *testing.T) {
 		type Logger struct {
 			Name string
@@ -44,21 +44,37 @@ func (l *ConsoleLogger) logf(msg string, args ...interface{}) {
 func (l *ConsoleLogger) LogEvent(event Event) {
 	switch e := event.(type) {
 	case *OnStartExecuting:
-		l.logf("HOOK OnStart\t\t%s executing (caller: %s)", e.FunctionName, e.CallerName)
+		l.logf("HOOK OnStart\t\t%s executing (caller: %s)%s", e.FunctionName, e.CallerName, fromModule(e.ModuleName))
 	case *OnStartExecuted:
 		if e.Err != nil {
-			l.logf("HOOK OnStart\t\t%s called by %s failed in %s: %+v", e.FunctionName, e.CallerName, e.Runtime, e.Err)
+			l.logf("HOOK OnStart\t\t%s called by %s%s failed in %s: %+v", e.FunctionName, e.CallerName, fromModule(e.ModuleName), e.Runtime, e.Err)
 		} else {
-			l.logf("HOOK OnStart\t\t%s called by %s ran successfully in %s", e.FunctionName, e.CallerName, e.Runtime)
+			l.logf("HOOK OnStart\t\t%s called by %s%s ran successfully in %s", e.FunctionName, e.CallerName, fromModule(e.ModuleName), e.Runtime)
 		}
 	case *OnStopExecuting:
-		l.logf("HOOK OnStop\t\t%s executing (caller: %s)", e.FunctionName, e.CallerName)
+		l.logf("HOOK OnStop\t\t%s executing (caller: %s)%s", e.FunctionName, e.CallerName, fromModule(e.ModuleName))
 	case *OnStopExecuted:
 		if e.Err != nil {
-			l.logf("HOOK OnStop\t\t%s called by %s failed in %s: %+v", e.FunctionName, e.CallerName, e.Runtime, e.Err)
+			l.logf("HOOK OnStop\t\t%s called by %s%s failed in %s: %+v", e.FunctionName, e.CallerName, fromModule(e.ModuleName), e.Runtime, e.Err)
 		} else {
-			l.logf("HOOK OnStop\t\t%s called by %s ran successfully in %s", e.FunctionName, e.CallerName, e.Runtime)
+			l.logf("HOOK OnStop\t\t%s called by %s%s ran successfully in %s", e.FunctionName, e.CallerName, fromModule(e.ModuleName), e.Runtime)
 		}
+	case *HookRuntimes:
+		if len(e.Hooks) == 0 {
+			break
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "HOOK %s\t\truntimes:", e.Method)
+		for _, h := range e.Hooks {
+			fmt.Fprintf(&sb, "\n\t%s called by %s%s ran in %s", h.FunctionName, h.CallerName, fromModule(h.ModuleName), h.Runtime)
+		}
+		for _, m := range e.Modules {
+			fmt.Fprintf(&sb, "\n\tmodule %q ran in %s", m.ModuleName, m.Runtime)
+		}
+		l.logf("%s", sb.String())
+	case *SlowHook:
+		l.logf("WARNING\t\tSlow %s hook %s called by %s ran in %s, exceeding %s",
+			e.Method, e.FunctionName, e.CallerName, e.Runtime, e.Threshold)
 	case *Supplied:
 		if e.Err != nil {
 			l.logf("ERROR\tFailed to supply %v: %+v", e.TypeName, e.Err)
@@ -112,11 +128,17 @@ func (l *ConsoleLogger) LogEvent(event Event) {
 			l.logf("ERROR\t\tfx.Invoke(%v) called from:\n%+vFailed: %+v", e.FunctionName, e.Trace, e.Err)
 		}
 	case *Stopping:
-		l.logf("%v", strings.ToUpper(e.Signal.String()))
+		if e.Cause != nil {
+			l.logf("%v\t\tCause: %v", strings.ToUpper(e.Signal.String()), e.Cause)
+		} else {
+			l.logf("%v", strings.ToUpper(e.Signal.String()))
+		}
 	case *Stopped:
 		if e.Err != nil {
 			l.logf("ERROR\t\tFailed to stop cleanly: %+v", e.Err)
 		}
+	case *Ready:
+		l.logf("READY")
 	case *RollingBack:
 		l.logf("ERROR\t\tStart failed, rolling back: %+v", e.StartErr)
 	case *RolledBack:
@@ -135,5 +157,16 @@ func (l *ConsoleLogger) LogEvent(event Event) {
 		} else {
 			l.logf("LOGGER\tInitialized custom logger from %v", e.ConstructorName)
 		}
+	case *LifecycleStateChanged:
+		l.logf("STATE\t\t%v -> %v", e.From, e.To)
+	}
+}
+
+// fromModule returns a suffix naming the module that an event came from, or
+// an empty string if the event didn't come from a module.
+func fromModule(name string) string {
+	if len(name) == 0 {
+		return ""
 	}
+	return fmt.Sprintf(" from module %q", name)
 }
@@ -63,6 +63,15 @@ func TestConsoleLogger(t *testing.T) {
 			},
 			want: "[Fx] HOOK OnStart		hook.onStart executing (caller: bytes.NewBuffer)\n",
 		},
+		{
+			name: "OnStartExecutingWithModule",
+			give: &OnStartExecuting{
+				FunctionName: "hook.onStart",
+				CallerName:   "bytes.NewBuffer",
+				ModuleName:   "myModule",
+			},
+			want: "[Fx] HOOK OnStart		hook.onStart executing (caller: bytes.NewBuffer) from module \"myModule\"\n",
+		},
 		{
 			name: "OnStopExecuting",
 			give: &OnStopExecuting{
@@ -275,6 +284,11 @@ func TestConsoleLogger(t *testing.T) {
 			give: &Stopping{Signal: os.Interrupt},
 			want: "[Fx] INTERRUPT\n",
 		},
+		{
+			name: "StoppingWithCause",
+			give: &Stopping{Signal: os.Interrupt, Cause: errors.New("health check failed")},
+			want: "[Fx] INTERRUPT\t\tCause: health check failed\n",
+		},
 		{
 			name: "Stopped",
 			give: &Stopped{Err: errors.New("some error")},
@@ -310,6 +324,55 @@ func TestConsoleLogger(t *testing.T) {
 			give: &Started{},
 			want: "[Fx] RUNNING\n",
 		},
+		{
+			name: "HookRuntimes",
+			give: &HookRuntimes{
+				Method: "OnStart",
+				Hooks: []HookRuntime{
+					{FunctionName: "hook.onStart1", CallerName: "bytes.NewBuffer", Runtime: 2 * time.Second},
+					{FunctionName: "hook.onStart2", CallerName: "bytes.NewReader", Runtime: time.Millisecond},
+				},
+			},
+			want: "[Fx] HOOK OnStart\t\truntimes:\n" +
+				"\thook.onStart1 called by bytes.NewBuffer ran in 2s\n" +
+				"\thook.onStart2 called by bytes.NewReader ran in 1ms\n",
+		},
+		{
+			name: "HookRuntimes/modules",
+			give: &HookRuntimes{
+				Method: "OnStop",
+				Hooks: []HookRuntime{
+					{FunctionName: "hook.onStop1", CallerName: "bytes.NewBuffer", ModuleName: "myModule", Runtime: time.Second},
+				},
+				Modules: []ModuleRuntime{
+					{ModuleName: "myModule", Runtime: time.Second},
+				},
+			},
+			want: "[Fx] HOOK OnStop\t\truntimes:\n" +
+				"\thook.onStop1 called by bytes.NewBuffer from module \"myModule\" ran in 1s\n" +
+				"\tmodule \"myModule\" ran in 1s\n",
+		},
+		{
+			name: "HookRuntimes/empty",
+			give: &HookRuntimes{Method: "OnStop"},
+			want: "",
+		},
+		{
+			name: "SlowHook",
+			give: &SlowHook{
+				Method:       "OnStop",
+				FunctionName: "hook.onStop1",
+				CallerName:   "bytes.NewBuffer",
+				Runtime:      2 * time.Second,
+				Threshold:    time.Second,
+			},
+			want: "[Fx] WARNING\t\tSlow OnStop hook hook.onStop1 called by bytes.NewBuffer ran in 2s, exceeding 1s\n",
+		},
+		{
+			name: "Ready",
+			give: &Ready{},
+			want: "[Fx] READY\n",
+		},
 		{
 			name: "CustomLoggerError",
 			give: &LoggerInitialized{Err: errors.New("great sadness")},
@@ -325,6 +388,11 @@ func TestConsoleLogger(t *testing.T) {
 			give: &LoggerInitialized{ConstructorName: "go.uber.org/fx/fxevent.TestConsoleLogger.func1()"},
 			want: "[Fx] LOGGER	Initialized custom logger from go.uber.org/fx/fxevent.TestConsoleLogger.func1()\n",
 		},
+		{
+			name: "LifecycleStateChanged",
+			give: &LifecycleStateChanged{From: "stopped", To: "starting"},
+			want: "[Fx] STATE		stopped -> starting\n",
+		},
 	}
 
 	for _, tt := range tests {
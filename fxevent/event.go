@@ -31,22 +31,26 @@ type Event interface {
 }
 
 // Passing events by type to make Event hashable in the future.
-func (*OnStartExecuting) event()  {}
-func (*OnStartExecuted) event()   {}
-func (*OnStopExecuting) event()   {}
-func (*OnStopExecuted) event()    {}
-func (*Supplied) event()          {}
-func (*Provided) event()          {}
-func (*Replaced) event()          {}
-func (*Decorated) event()         {}
-func (*Invoking) event()          {}
-func (*Invoked) event()           {}
-func (*Stopping) event()          {}
-func (*Stopped) event()           {}
-func (*RollingBack) event()       {}
-func (*RolledBack) event()        {}
-func (*Started) event()           {}
-func (*LoggerInitialized) event() {}
+func (*OnStartExecuting) event()      {}
+func (*OnStartExecuted) event()       {}
+func (*OnStopExecuting) event()       {}
+func (*OnStopExecuted) event()        {}
+func (*Supplied) event()              {}
+func (*Provided) event()              {}
+func (*Replaced) event()              {}
+func (*Decorated) event()             {}
+func (*Invoking) event()              {}
+func (*Invoked) event()               {}
+func (*Stopping) event()              {}
+func (*Stopped) event()               {}
+func (*RollingBack) event()           {}
+func (*RolledBack) event()            {}
+func (*Started) event()               {}
+func (*LoggerInitialized) event()     {}
+func (*LifecycleStateChanged) event() {}
+func (*Ready) event()                 {}
+func (*HookRuntimes) event()          {}
+func (*SlowHook) event()              {}
 
 // OnStartExecuting is emitted before an OnStart hook is exeucted.
 type OnStartExecuting struct {
@@ -56,6 +60,9 @@ type OnStartExecuting struct {
 	// CallerName is the name of the function that scheduled the hook for
 	// execution.
 	CallerName string
+
+	// ModuleName is the name of the module that appended the hook, if any.
+	ModuleName string
 }
 
 // OnStartExecuted is emitted after an OnStart hook has been executed.
@@ -67,6 +74,9 @@ type OnStartExecuted struct {
 	// execution.
 	CallerName string
 
+	// ModuleName is the name of the module that appended the hook, if any.
+	ModuleName string
+
 	// Method specifies the kind of the hook. This is one of "OnStart" and
 	// "OnStop".
 	Method string
@@ -86,6 +96,9 @@ type OnStopExecuting struct {
 	// CallerName is the name of the function that scheduled the hook for
 	// execution.
 	CallerName string
+
+	// ModuleName is the name of the module that appended the hook, if any.
+	ModuleName string
 }
 
 // OnStopExecuted is emitted after an OnStop hook has been executed.
@@ -97,6 +110,9 @@ type OnStopExecuted struct {
 	// execution.
 	CallerName string
 
+	// ModuleName is the name of the module that appended the hook, if any.
+	ModuleName string
+
 	// Runtime specifies how long it took to run this hook.
 	Runtime time.Duration
 
@@ -104,6 +120,67 @@ type OnStopExecuted struct {
 	Err error
 }
 
+// HookRuntime describes how long a single lifecycle hook ran.
+type HookRuntime struct {
+	// FunctionName is the name of the function that ran as the hook.
+	FunctionName string
+
+	// CallerName is the name of the function that scheduled the hook.
+	CallerName string
+
+	// ModuleName is the name of the module that appended the hook, if any.
+	ModuleName string
+
+	// Runtime is how long the hook ran.
+	Runtime time.Duration
+}
+
+// ModuleRuntime describes how long the lifecycle hooks appended by a single
+// module ran in total.
+type ModuleRuntime struct {
+	// ModuleName is the name of the module.
+	ModuleName string
+
+	// Runtime is the sum of the runtimes of the module's hooks.
+	Runtime time.Duration
+}
+
+// HookRuntimes is emitted at the end of the application's Start and Stop with
+// the runtime of every OnStart or OnStop hook that finished running, slowest
+// first.
+type HookRuntimes struct {
+	// Method is the kind of hooks that ran: "OnStart" or "OnStop".
+	Method string
+
+	// Hooks lists the hooks that finished running, sorted by runtime in
+	// descending order.
+	Hooks []HookRuntime
+
+	// Modules lists the total runtime of the hooks appended by each
+	// module, sorted by runtime in descending order. Hooks appended outside
+	// of any module aren't included.
+	Modules []ModuleRuntime
+}
+
+// SlowHook is emitted when an OnStart or OnStop hook runs for longer than the
+// threshold set with fx.SlowHookThreshold.
+type SlowHook struct {
+	// Method is the kind of hook that ran: "OnStart" or "OnStop".
+	Method string
+
+	// FunctionName is the name of the function that ran as the hook.
+	FunctionName string
+
+	// CallerName is the name of the function that scheduled the hook.
+	CallerName string
+
+	// Runtime is how long the hook ran.
+	Runtime time.Duration
+
+	// Threshold is the runtime above which hooks are reported as slow.
+	Threshold time.Duration
+}
+
 // Supplied is emitted after a value is added with fx.Supply.
 type Supplied struct {
 	// TypeName is the name of the type of value that was added.
@@ -202,6 +279,10 @@ type Started struct {
 type Stopping struct {
 	// Signal is the signal that caused this shutdown.
 	Signal os.Signal
+
+	// Cause is the reason for the shutdown, if one was given to
+	// fx.Shutdowner with fx.ShutdownCause.
+	Cause error
 }
 
 // Stopped is emitted when the application has finished shutting down, whether
@@ -211,6 +292,10 @@ type Stopped struct {
 	Err error
 }
 
+// Ready is emitted when the application has started and all of its
+// readiness checks have passed.
+type Ready struct{}
+
 // RollingBack is emitted when the application failed to start up due to an
 // error, and is being rolled back.
 type RollingBack struct {
@@ -235,3 +320,13 @@ type LoggerInitialized struct {
 	// Err is non-nil if the logger failed to build.
 	Err error
 }
+
+// LifecycleStateChanged is emitted when the application's lifecycle moves
+// from one state to another, for example from "starting" to "started".
+type LifecycleStateChanged struct {
+	// From is the state the lifecycle was in.
+	From string
+
+	// To is the state the lifecycle is now in.
+	To string
+}
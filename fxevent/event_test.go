@@ -47,6 +47,10 @@ func TestForCoverage(t *testing.T) {
 		&RolledBack{},
 		&Started{},
 		&LoggerInitialized{},
+		&LifecycleStateChanged{},
+		&Ready{},
+		&HookRuntimes{},
+		&SlowHook{},
 	}
 
 	for _, e := range events {
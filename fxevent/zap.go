@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // ZapLogger is an Fx event logger that logs events to Zap.
@@ -40,18 +41,21 @@ func (l *ZapLogger) LogEvent(event Event) {
 		l.Logger.Info("OnStart hook executing",
 			zap.String("callee", e.FunctionName),
 			zap.String("caller", e.CallerName),
+			moduleField(e.ModuleName),
 		)
 	case *OnStartExecuted:
 		if e.Err != nil {
 			l.Logger.Info("OnStart hook failed",
 				zap.String("callee", e.FunctionName),
 				zap.String("caller", e.CallerName),
+				moduleField(e.ModuleName),
 				zap.Error(e.Err),
 			)
 		} else {
 			l.Logger.Info("OnStart hook executed",
 				zap.String("callee", e.FunctionName),
 				zap.String("caller", e.CallerName),
+				moduleField(e.ModuleName),
 				zap.String("runtime", e.Runtime.String()),
 			)
 		}
@@ -59,21 +63,39 @@ func (l *ZapLogger) LogEvent(event Event) {
 		l.Logger.Info("OnStop hook executing",
 			zap.String("callee", e.FunctionName),
 			zap.String("caller", e.CallerName),
+			moduleField(e.ModuleName),
 		)
 	case *OnStopExecuted:
 		if e.Err != nil {
 			l.Logger.Info("OnStop hook failed",
 				zap.String("callee", e.FunctionName),
 				zap.String("caller", e.CallerName),
+				moduleField(e.ModuleName),
 				zap.Error(e.Err),
 			)
 		} else {
 			l.Logger.Info("OnStop hook executed",
 				zap.String("callee", e.FunctionName),
 				zap.String("caller", e.CallerName),
+				moduleField(e.ModuleName),
 				zap.String("runtime", e.Runtime.String()),
 			)
 		}
+	case *HookRuntimes:
+		if len(e.Hooks) == 0 {
+			break
+		}
+		l.Logger.Info(e.Method+" hook runtimes",
+			zap.Array("hooks", hookRuntimes(e.Hooks)),
+			moduleRuntimesField(e.Modules),
+		)
+	case *SlowHook:
+		l.Logger.Warn("slow "+e.Method+" hook",
+			zap.String("callee", e.FunctionName),
+			zap.String("caller", e.CallerName),
+			zap.String("runtime", e.Runtime.String()),
+			zap.String("threshold", e.Threshold.String()),
+		)
 	case *Supplied:
 		l.Logger.Info("supplied",
 			zap.String("type", e.TypeName),
@@ -134,11 +156,14 @@ func (l *ZapLogger) LogEvent(event Event) {
 		}
 	case *Stopping:
 		l.Logger.Info("received signal",
-			zap.String("signal", strings.ToUpper(e.Signal.String())))
+			zap.String("signal", strings.ToUpper(e.Signal.String())),
+			causeField(e.Cause))
 	case *Stopped:
 		if e.Err != nil {
 			l.Logger.Error("stop failed", zap.Error(e.Err))
 		}
+	case *Ready:
+		l.Logger.Info("ready")
 	case *RollingBack:
 		l.Logger.Error("start failed, rolling back", zap.Error(e.StartErr))
 	case *RolledBack:
@@ -157,6 +182,10 @@ func (l *ZapLogger) LogEvent(event Event) {
 		} else {
 			l.Logger.Info("initialized custom fxevent.Logger", zap.String("function", e.ConstructorName))
 		}
+	case *LifecycleStateChanged:
+		l.Logger.Info("lifecycle state changed",
+			zap.String("from", e.From),
+			zap.String("to", e.To))
 	}
 }
 
@@ -166,3 +195,49 @@ func moduleField(name string) zap.Field {
 	}
 	return zap.String("module", name)
 }
+
+func causeField(err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.NamedError("cause", err)
+}
+
+type hookRuntimes []HookRuntime
+
+func (hs hookRuntimes) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, h := range hs {
+		h := h
+		enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("callee", h.FunctionName)
+			enc.AddString("caller", h.CallerName)
+			if len(h.ModuleName) > 0 {
+				enc.AddString("module", h.ModuleName)
+			}
+			enc.AddString("runtime", h.Runtime.String())
+			return nil
+		}))
+	}
+	return nil
+}
+
+func moduleRuntimesField(ms []ModuleRuntime) zap.Field {
+	if len(ms) == 0 {
+		return zap.Skip()
+	}
+	return zap.Array("modules", moduleRuntimes(ms))
+}
+
+type moduleRuntimes []ModuleRuntime
+
+func (ms moduleRuntimes) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, m := range ms {
+		m := m
+		enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("module", m.ModuleName)
+			enc.AddString("runtime", m.Runtime.String())
+			return nil
+		}))
+	}
+	return nil
+}
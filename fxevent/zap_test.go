@@ -56,6 +56,20 @@ func TestZapLogger(t *testing.T) {
 				"callee": "hook.onStart",
 			},
 		},
+		{
+			name: "OnStartExecutingWithModule",
+			give: &OnStartExecuting{
+				FunctionName: "hook.onStart",
+				CallerName:   "bytes.NewBuffer",
+				ModuleName:   "myModule",
+			},
+			wantMessage: "OnStart hook executing",
+			wantFields: map[string]interface{}{
+				"caller": "bytes.NewBuffer",
+				"callee": "hook.onStart",
+				"module": "myModule",
+			},
+		},
 		{
 			name: "OnStopExecuting",
 			give: &OnStopExecuting{
@@ -227,6 +241,15 @@ func TestZapLogger(t *testing.T) {
 				"function": "bytes.NewBuffer()",
 			},
 		},
+		{
+			name:        "LifecycleStateChanged",
+			give:        &LifecycleStateChanged{From: "stopped", To: "starting"},
+			wantMessage: "lifecycle state changed",
+			wantFields: map[string]interface{}{
+				"from": "stopped",
+				"to":   "starting",
+			},
+		},
 		{
 			name:        "StartError",
 			give:        &Started{Err: someError},
@@ -243,6 +266,15 @@ func TestZapLogger(t *testing.T) {
 				"signal": "INTERRUPT",
 			},
 		},
+		{
+			name:        "StoppingWithCause",
+			give:        &Stopping{Signal: os.Interrupt, Cause: someError},
+			wantMessage: "received signal",
+			wantFields: map[string]interface{}{
+				"signal": "INTERRUPT",
+				"cause":  "some error",
+			},
+		},
 		{
 			name:        "Stopped",
 			give:        &Stopped{Err: someError},
@@ -273,6 +305,77 @@ func TestZapLogger(t *testing.T) {
 			wantMessage: "started",
 			wantFields:  map[string]interface{}{},
 		},
+		{
+			name: "HookRuntimes",
+			give: &HookRuntimes{
+				Method: "OnStart",
+				Hooks: []HookRuntime{
+					{FunctionName: "hook.onStart1", CallerName: "bytes.NewBuffer", Runtime: 2 * time.Second},
+				},
+			},
+			wantMessage: "OnStart hook runtimes",
+			wantFields: map[string]interface{}{
+				"hooks": []interface{}{
+					map[string]interface{}{
+						"callee":  "hook.onStart1",
+						"caller":  "bytes.NewBuffer",
+						"runtime": "2s",
+					},
+				},
+			},
+		},
+		{
+			name: "HookRuntimes/modules",
+			give: &HookRuntimes{
+				Method: "OnStop",
+				Hooks: []HookRuntime{
+					{FunctionName: "hook.onStop1", CallerName: "bytes.NewBuffer", ModuleName: "myModule", Runtime: time.Second},
+				},
+				Modules: []ModuleRuntime{
+					{ModuleName: "myModule", Runtime: time.Second},
+				},
+			},
+			wantMessage: "OnStop hook runtimes",
+			wantFields: map[string]interface{}{
+				"hooks": []interface{}{
+					map[string]interface{}{
+						"callee":  "hook.onStop1",
+						"caller":  "bytes.NewBuffer",
+						"module":  "myModule",
+						"runtime": "1s",
+					},
+				},
+				"modules": []interface{}{
+					map[string]interface{}{
+						"module":  "myModule",
+						"runtime": "1s",
+					},
+				},
+			},
+		},
+		{
+			name: "SlowHook",
+			give: &SlowHook{
+				Method:       "OnStop",
+				FunctionName: "hook.onStop1",
+				CallerName:   "bytes.NewBuffer",
+				Runtime:      2 * time.Second,
+				Threshold:    time.Second,
+			},
+			wantMessage: "slow OnStop hook",
+			wantFields: map[string]interface{}{
+				"callee":    "hook.onStop1",
+				"caller":    "bytes.NewBuffer",
+				"runtime":   "2s",
+				"threshold": "1s",
+			},
+		},
+		{
+			name:        "Ready",
+			give:        &Ready{},
+			wantMessage: "ready",
+			wantFields:  map[string]interface{}{},
+		},
 		{
 			name:        "LoggerInitialized Error",
 			give:        &LoggerInitialized{Err: someError},
@@ -307,4 +410,12 @@ func TestZapLogger(t *testing.T) {
 			assert.Equal(t, tt.wantFields, got.ContextMap())
 		})
 	}
+
+	t.Run("HookRuntimes/empty", func(t *testing.T) {
+		t.Parallel()
+
+		core, observedLogs := observer.New(zap.DebugLevel)
+		(&ZapLogger{Logger: zap.New(core)}).LogEvent(&HookRuntimes{Method: "OnStart"})
+		assert.Empty(t, observedLogs.TakeAll(), "no hooks ran, so nothing must be logged")
+	})
 }
@@ -131,7 +131,9 @@ func (l *Lifecycle) RequireStop() {
 // Append registers a new Hook.
 func (l *Lifecycle) Append(h fx.Hook) {
 	l.lc.Append(lifecycle.Hook{
-		OnStart: h.OnStart,
-		OnStop:  h.OnStop,
+		OnStart:        h.OnStart,
+		OnStop:         h.OnStop,
+		OnStartTimeout: h.OnStartTimeout,
+		OnStopTimeout:  h.OnStopTimeout,
 	})
 }
@@ -0,0 +1,112 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.21
+// +build go1.21
+
+// Type parameters are gated on go1.21 rather than go1.18 because the module
+// still declares go 1.17, and only go1.21+ toolchains honor a newer language
+// version requested by a build constraint.
+
+package fx
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.uber.org/fx/internal/fxreflect"
+)
+
+// ProvideAs registers a constructor like Provide, but exposes its result to
+// the container as the interface type I instead of the concrete type it
+// returns.
+//
+//	fx.ProvideAs[io.Writer](newBuffer)
+//
+// is equivalent to
+//
+//	fx.Provide(fx.Annotate(newBuffer, fx.As(new(io.Writer))))
+//
+// The interface is named by the type parameter, so it's no longer possible to
+// pass the interface value instead of a pointer to it, or a pointer to the
+// wrong kind of type. ProvideAs doesn't check types at compile time: Go's
+// type parameters can't require the constructor's result to implement I. New
+// reports an error if I isn't an interface, or if the constructor's result
+// doesn't implement it.
+func ProvideAs[I any](constructor interface{}) Option {
+	target := Annotate(constructor, As(new(I)))
+	if typ := reflect.TypeOf((*I)(nil)).Elem(); typ.Kind() != reflect.Interface {
+		target = annotationError{
+			target: constructor,
+			err:    fmt.Errorf("fx.ProvideAs requires an interface type parameter, got %v", typ),
+		}
+	}
+
+	return provideOption{
+		Targets: []interface{}{target},
+		Stack:   fxreflect.CallerStack(1, 0),
+	}
+}
+
+// SupplyTyped provides an instantiated value like Supply, but as the type T
+// rather than the most specific type of the value. The compiler checks that
+// the value is assignable to T.
+//
+//	var handler http.Handler = http.HandlerFunc(f)
+//	fx.SupplyTyped[http.Handler](handler)
+//
+// is equivalent to
+//
+//	fx.Provide(func() http.Handler { return handler })
+//
+// SupplyTyped panics if T is the error type.
+func SupplyTyped[T any](value T) Option {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ == _typeOfError {
+		panic("error type passed to fx.SupplyTyped")
+	}
+
+	returnValues := []reflect.Value{reflect.ValueOf(&value).Elem()}
+	ft := reflect.FuncOf([]reflect.Type{}, []reflect.Type{typ}, false)
+	fv := reflect.MakeFunc(ft, func([]reflect.Value) []reflect.Value {
+		return returnValues
+	})
+
+	return supplyOption{
+		Targets: []interface{}{fv.Interface()},
+		Types:   []reflect.Type{typ},
+		Stack:   fxreflect.CallerStack(1, 0),
+	}
+}
+
+// Get retrieves a value of type T from the container of an application
+// returned by New, like Resolve.
+//
+//	app := fx.New(fx.Provide(newLogger))
+//	logger, err := fx.Get[*zap.Logger](app)
+//
+// Get returns the same errors as Resolve.
+func Get[T any](app *App) (T, error) {
+	var value T
+	if err := app.Resolve(&value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
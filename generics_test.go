@@ -0,0 +1,153 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.21
+// +build go1.21
+
+package fx_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestProvideAs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		buf := new(bytes.Buffer)
+		var w io.Writer
+		app := fxtest.New(t,
+			fx.ProvideAs[io.Writer](func() *bytes.Buffer { return buf }),
+			fx.Populate(&w),
+		)
+		defer app.RequireStart().RequireStop()
+
+		assert.Same(t, buf, w)
+	})
+
+	t.Run("does not implement", func(t *testing.T) {
+		t.Parallel()
+
+		app := NewForTest(t,
+			fx.ProvideAs[io.Reader](func() struct{} { return struct{}{} }),
+		)
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid fx.As: struct {} does not implement")
+	})
+
+	t.Run("not an interface", func(t *testing.T) {
+		t.Parallel()
+
+		app := NewForTest(t,
+			fx.ProvideAs[int](func() int { return 42 }),
+		)
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fx.ProvideAs requires an interface type parameter, got int")
+
+		var annErr *fx.AnnotationError
+		assert.ErrorAs(t, err, &annErr)
+	})
+}
+
+func TestSupplyTyped(t *testing.T) {
+	t.Parallel()
+
+	t.Run("interface", func(t *testing.T) {
+		t.Parallel()
+
+		buf := new(bytes.Buffer)
+		var w io.Writer
+		app := fxtest.New(t,
+			fx.SupplyTyped[io.Writer](buf),
+			fx.Populate(&w),
+		)
+		defer app.RequireStart().RequireStop()
+
+		assert.Same(t, buf, w)
+	})
+
+	t.Run("nil interface", func(t *testing.T) {
+		t.Parallel()
+
+		w := io.Writer(new(bytes.Buffer))
+		app := fxtest.New(t,
+			fx.SupplyTyped[io.Writer](nil),
+			fx.Populate(&w),
+		)
+		defer app.RequireStart().RequireStop()
+
+		assert.Nil(t, w)
+	})
+
+	t.Run("error type", func(t *testing.T) {
+		t.Parallel()
+
+		assert.PanicsWithValue(t, "error type passed to fx.SupplyTyped", func() {
+			fx.SupplyTyped[error](errors.New("great sadness"))
+		})
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	type A struct{ name string }
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		app := fxtest.New(t,
+			fx.Provide(func() *A { return &A{name: "foo"} }),
+		)
+		a, err := fx.Get[*A](app.App)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", a.name)
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+		t.Parallel()
+
+		app := fxtest.New(t)
+		_, err := fx.Get[*A](app.App)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: *fx_test.A")
+	})
+
+	t.Run("app error", func(t *testing.T) {
+		t.Parallel()
+
+		app := NewForTest(t, fx.Error(errors.New("great sadness")))
+		_, err := fx.Get[*A](app)
+		assert.ErrorIs(t, err, app.Err())
+	})
+}
@@ -23,6 +23,7 @@ package fxreflect
 import (
 	"fmt"
 	"io"
+	"reflect"
 	"runtime"
 	"strings"
 )
@@ -147,3 +148,32 @@ func CallerStack(skip, depth int) Stack {
 	}
 	return result
 }
+
+// OnStack reports whether the function fn is being called, directly or
+// indirectly, by the calling goroutine.
+func OnStack(fn interface{}) bool {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+
+	// Grow the buffer until it holds the whole stack.
+	pcs := make([]uintptr, _defaultCallersDepth)
+	for {
+		// +2 to skip this frame and runtime.Callers.
+		n := runtime.Callers(2, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if f.Function == name {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
@@ -258,3 +258,35 @@ path/to/another/module.AnotherFunction()
 	})
 
 }
+
+func callOnStack(fn func()) { fn() }
+
+func TestOnStack(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Direct", func(t *testing.T) {
+		var onStack bool
+		callOnStack(func() { onStack = OnStack(callOnStack) })
+		assert.True(t, onStack)
+	})
+
+	t.Run("Indirect", func(t *testing.T) {
+		var onStack bool
+		callOnStack(func() {
+			func() { onStack = OnStack(callOnStack) }()
+		})
+		assert.True(t, onStack)
+	})
+
+	t.Run("NotCalled", func(t *testing.T) {
+		assert.False(t, OnStack(callOnStack))
+	})
+
+	t.Run("OtherGoroutine", func(t *testing.T) {
+		onStack := make(chan bool)
+		callOnStack(func() {
+			go func() { onStack <- OnStack(callOnStack) }()
+		})
+		assert.False(t, <-onStack)
+	})
+}
@@ -25,6 +25,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -35,13 +36,114 @@ import (
 	"go.uber.org/multierr"
 )
 
+// errHookExited is returned when a hook running on its own goroutine exits
+// that goroutine without returning.
+var errHookExited = errors.New("goroutine exited without returning")
+
 // A Hook is a pair of start and stop callbacks, either of which can be nil,
 // plus a string identifying the supplier of the hook.
 type Hook struct {
 	OnStart func(context.Context) error
 	OnStop  func(context.Context) error
 
-	callerFrame fxreflect.Frame
+	// If non-zero, OnStartTimeout and OnStopTimeout limit how long the
+	// corresponding callback may run.
+	OnStartTimeout time.Duration
+	OnStopTimeout  time.Duration
+
+	// Owner identifies the component that appended this hook. It is used
+	// only by parallel lifecycles to decide which hooks may run
+	// concurrently. Hooks without an Owner are ordered with respect to all
+	// other hooks.
+	Owner *Owner
+
+	// Module is the name of the fx.Module that appended this hook, or
+	// empty if the hook was appended outside of any module.
+	Module string
+
+	// CallerFrame is the location reported for this hook. If unset, Append
+	// sets it to the caller of Append.
+	CallerFrame fxreflect.Frame
+}
+
+// Owner is a component that appends hooks to a Lifecycle, such as a
+// constructor. It records the other Owners it depends on.
+type Owner struct {
+	deps map[*Owner]struct{} // transitive dependencies
+}
+
+// NewOwner builds an Owner that depends on the given Owners, and on
+// everything they depend on.
+func NewOwner(deps ...*Owner) *Owner {
+	o := &Owner{deps: make(map[*Owner]struct{})}
+	for _, d := range deps {
+		if d == nil {
+			continue
+		}
+		o.deps[d] = struct{}{}
+		for dd := range d.deps {
+			o.deps[dd] = struct{}{}
+		}
+	}
+	return o
+}
+
+func (o *Owner) dependsOn(other *Owner) bool {
+	_, ok := o.deps[other]
+	return ok
+}
+
+// State is the state of a Lifecycle.
+//
+// The values of State mirror those of fx.State; keep the two in sync.
+type State int
+
+const (
+	// Stopped is the state of a Lifecycle that hasn't been started, or
+	// that has finished stopping.
+	Stopped State = iota
+
+	// Starting is the state of a Lifecycle that is running its OnStart
+	// hooks. A Lifecycle whose Start failed remains Starting until it's
+	// stopped.
+	Starting
+
+	// Started is the state of a Lifecycle whose OnStart hooks all
+	// succeeded.
+	Started
+
+	// Stopping is the state of a Lifecycle that is running its OnStop
+	// hooks.
+	Stopping
+)
+
+func (s State) String() string {
+	switch s {
+	case Stopped:
+		return "stopped"
+	case Starting:
+		return "starting"
+	case Started:
+		return "started"
+	case Stopping:
+		return "stopping"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// TransitionError is returned by Start and Stop when the Lifecycle is in a
+// state that doesn't allow the requested operation.
+type TransitionError struct {
+	// Op is the requested operation: "start" or "stop".
+	Op string
+
+	// State is the state the Lifecycle was in.
+	State State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot %v lifecycle: lifecycle is %v", e.Op, e.State)
 }
 
 // Lifecycle coordinates application lifecycle hooks.
@@ -49,11 +151,33 @@ type Lifecycle struct {
 	clock        fxclock.Clock
 	logger       fxevent.Logger
 	hooks        []Hook
+	state        State
 	numStarted   int
 	startRecords HookRecords
 	stopRecords  HookRecords
 	runningHook  Hook
 	mu           sync.Mutex
+
+	// Held while OnStart hooks run, so that hooks appended after Start
+	// began are started exactly once.
+	startMu sync.Mutex
+
+	// Set while StartAppended runs hooks, up to but excluding startEnd.
+	// Guarded by mu.
+	startingAppended bool
+	startEnd         int
+
+	// Hooks that run for longer than this are reported as slow. Zero
+	// disables reporting.
+	slowHookThreshold time.Duration
+
+	// Called by Start once the lifecycle is Starting. May be nil.
+	onStarting func()
+
+	// Used only if the lifecycle runs hooks in parallel.
+	parallel     bool
+	started      []bool       // started[i] is true if hooks[i] started
+	runningHooks map[int]Hook // hooks that are currently running
 }
 
 // New constructs a new Lifecycle.
@@ -61,102 +185,318 @@ func New(logger fxevent.Logger, clock fxclock.Clock) *Lifecycle {
 	return &Lifecycle{logger: logger, clock: clock}
 }
 
+// SetParallel specifies whether hooks whose Owners don't depend on each
+// other run concurrently.
+func (l *Lifecycle) SetParallel(parallel bool) {
+	l.parallel = parallel
+}
+
+// State reports the current state of the lifecycle.
+func (l *Lifecycle) State() State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// transition moves the lifecycle to the state to if it's currently in one of
+// the states in from. Otherwise, it returns a TransitionError for op.
+func (l *Lifecycle) transition(op string, to State, from ...State) error {
+	l.mu.Lock()
+	cur := l.state
+	allowed := false
+	for _, s := range from {
+		if s == cur {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		l.mu.Unlock()
+		return &TransitionError{Op: op, State: cur}
+	}
+	l.state = to
+	l.mu.Unlock()
+
+	l.logTransition(cur, to)
+	return nil
+}
+
+func (l *Lifecycle) logTransition(from, to State) {
+	l.logger.LogEvent(&fxevent.LifecycleStateChanged{
+		From: from.String(),
+		To:   to.String(),
+	})
+}
+
+// SetOnStarting specifies a function that Start calls once the lifecycle is
+// Starting, before any OnStart hooks run. It isn't called if Start fails
+// because the lifecycle isn't Stopped.
+func (l *Lifecycle) SetOnStarting(fn func()) {
+	l.onStarting = fn
+}
+
+// SetSlowHookThreshold specifies the runtime above which a hook is reported
+// with an fxevent.SlowHook event. Zero disables these reports.
+func (l *Lifecycle) SetSlowHookThreshold(threshold time.Duration) {
+	l.slowHookThreshold = threshold
+}
+
 // Append adds a Hook to the lifecycle.
+//
+// Hooks may be appended at any time. A hook appended while the lifecycle is
+// Starting runs after all other OnStart hooks. A hook appended once the
+// lifecycle is Started doesn't run until StartAppended is called, and hooks
+// appended while Stopping or Stopped run on the next Start.
 func (l *Lifecycle) Append(hook Hook) {
 	// Save the caller's stack frame to report file/line number.
-	if f := fxreflect.CallerStack(2, 0); len(f) > 0 {
-		hook.callerFrame = f[0]
+	if f := fxreflect.CallerStack(2, 0); len(f) > 0 && hook.CallerFrame == (fxreflect.Frame{}) {
+		hook.CallerFrame = f[0]
 	}
+	l.mu.Lock()
 	l.hooks = append(l.hooks, hook)
+	l.mu.Unlock()
 }
 
 // Start runs all OnStart hooks, returning immediately if it encounters an
-// error.
-func (l *Lifecycle) Start(ctx context.Context) error {
+// error. The lifecycle must be Stopped: either new, or stopped after a
+// previous Start, in which case all hooks run again.
+func (l *Lifecycle) Start(ctx context.Context) (err error) {
 	if ctx == nil {
 		return errors.New("called OnStart with nil context")
 	}
 
+	if err := l.transition("start", Starting, Stopped); err != nil {
+		return err
+	}
+	if l.onStarting != nil {
+		l.onStarting()
+	}
+
+	l.startMu.Lock()
+	defer l.startMu.Unlock()
+
+	// Forget everything about the previous run, if any, so that a
+	// restarted lifecycle runs all hooks again.
 	l.mu.Lock()
+	l.numStarted = 0
+	l.runningHook = Hook{}
 	l.startRecords = make(HookRecords, 0, len(l.hooks))
+	l.stopRecords = nil
 	l.mu.Unlock()
 
-	for _, hook := range l.hooks {
+	if l.parallel {
+		if err := l.startParallel(ctx); err != nil {
+			return err
+		}
+	}
+
+	for {
+		if err := l.startAppended(ctx, false /* dropFailed */); err != nil {
+			return err
+		}
+
+		// Hooks may have been appended since startAppended returned.
+		// Only become Started once all of them have run, so that
+		// StartAppended runs anything appended later.
+		l.mu.Lock()
+		if l.numStarted == len(l.hooks) {
+			l.state = Started
+			l.mu.Unlock()
+			l.logTransition(Starting, Started)
+			return nil
+		}
+		l.mu.Unlock()
+	}
+}
+
+// StartAppended runs the OnStart hooks of hooks appended since the lifecycle
+// was Started, in order. It does nothing unless the lifecycle is Started:
+// hooks appended while Starting are run by Start, and other hooks run on the
+// next Start.
+//
+// If a hook fails, StartAppended returns its error, and the hook is removed
+// from the lifecycle so that its OnStop doesn't run.
+//
+// Calls from other goroutines wait for a running StartAppended to return, and
+// then run the hooks that are still pending. A call made from a hook that
+// StartAppended is running can't wait for it, so it returns immediately, and
+// the running call runs the hooks appended so far once the hook returns.
+func (l *Lifecycle) StartAppended(ctx context.Context) error {
+	if ctx == nil {
+		return errors.New("called OnStart with nil context")
+	}
+
+	l.mu.Lock()
+	if l.state != Started {
+		l.mu.Unlock()
+		return nil
+	}
+	nested := l.startingAppended
+	l.mu.Unlock()
+
+	// Only a goroutine running one of the hooks can be nested inside the
+	// running call, so walk the stack only if there is one.
+	if nested && fxreflect.OnStack(callHook) {
+		l.mu.Lock()
+		l.startEnd = len(l.hooks)
+		l.mu.Unlock()
+		return nil
+	}
+
+	l.startMu.Lock()
+	defer l.startMu.Unlock()
+
+	l.mu.Lock()
+	if l.state != Started {
+		// Stopped while waiting for startMu.
+		l.mu.Unlock()
+		return nil
+	}
+	l.startingAppended = true
+	l.startEnd = len(l.hooks)
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.startingAppended = false
+		l.mu.Unlock()
+	}()
+	return l.startAppended(ctx, true /* dropFailed */)
+}
+
+// startAppended runs, in order, the OnStart hooks that haven't started yet.
+// Inside StartAppended, it stops at startEnd. Otherwise, it runs all hooks,
+// including hooks appended while it runs. If dropFailed is set, a hook whose
+// OnStart fails is removed from the lifecycle. The caller must hold startMu.
+func (l *Lifecycle) startAppended(ctx context.Context, dropFailed bool) error {
+	for {
+		l.mu.Lock()
+		i := l.numStarted
+		end := len(l.hooks)
+		if l.startingAppended {
+			end = l.startEnd
+		}
+		if i >= end {
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
 		// if ctx has cancelled, bail out of the loop.
 		if err := ctx.Err(); err != nil {
 			return err
 		}
 
+		l.mu.Lock()
+		hook := l.hooks[i]
 		if hook.OnStart != nil {
-			l.mu.Lock()
 			l.runningHook = hook
-			l.mu.Unlock()
+		}
+		l.mu.Unlock()
 
+		if hook.OnStart != nil {
 			runtime, err := l.runStartHook(ctx, hook)
 			if err != nil {
+				if dropFailed {
+					l.mu.Lock()
+					// Copy rather than shift in place: Stop may
+					// be looking at the old slice.
+					l.hooks = append(l.hooks[:i:i], l.hooks[i+1:]...)
+					l.startEnd--
+					l.mu.Unlock()
+				}
 				return err
 			}
 
 			l.mu.Lock()
 			l.startRecords = append(l.startRecords, HookRecord{
-				CallerFrame: hook.callerFrame,
+				CallerFrame: hook.CallerFrame,
 				Func:        hook.OnStart,
+				Module:      hook.Module,
 				Runtime:     runtime,
 			})
 			l.mu.Unlock()
 		}
+
+		l.mu.Lock()
 		l.numStarted++
+		if l.parallel {
+			l.started = append(l.started, true)
+		}
+		l.mu.Unlock()
 	}
-
-	return nil
 }
 
 func (l *Lifecycle) runStartHook(ctx context.Context, hook Hook) (runtime time.Duration, err error) {
 	funcName := fxreflect.FuncName(hook.OnStart)
 	l.logger.LogEvent(&fxevent.OnStartExecuting{
-		CallerName:   hook.callerFrame.Function,
+		CallerName:   hook.CallerFrame.Function,
 		FunctionName: funcName,
+		ModuleName:   hook.Module,
 	})
 	defer func() {
 		l.logger.LogEvent(&fxevent.OnStartExecuted{
-			CallerName:   hook.callerFrame.Function,
+			CallerName:   hook.CallerFrame.Function,
 			FunctionName: funcName,
+			ModuleName:   hook.Module,
 			Runtime:      runtime,
 			Err:          err,
 		})
+		l.reportSlowHook("OnStart", funcName, hook, runtime)
 	}()
 
 	begin := l.clock.Now()
-	err = hook.OnStart(ctx)
+	err = l.runHookFunc(ctx, "OnStart", hook.OnStart, hook.OnStartTimeout, hook.CallerFrame)
 	return l.clock.Since(begin), err
 }
 
 // Stop runs any OnStop hooks whose OnStart counterpart succeeded. OnStop
-// hooks run in reverse order.
+// hooks run in reverse order. The lifecycle must be Starting or Started, and
+// is Stopped once Stop returns, even if some hooks failed.
 func (l *Lifecycle) Stop(ctx context.Context) error {
 	if ctx == nil {
 		return errors.New("called OnStop with nil context")
 	}
 
+	if err := l.transition("stop", Stopping, Starting, Started); err != nil {
+		return err
+	}
+	defer l.transition("stop", Stopped, Stopping)
+
 	l.mu.Lock()
 	l.stopRecords = make(HookRecords, 0, l.numStarted)
 	l.mu.Unlock()
 
+	if l.parallel {
+		return l.stopParallel(ctx)
+	}
+
 	// Run backward from last successful OnStart.
 	var errs []error
-	for ; l.numStarted > 0; l.numStarted-- {
+	for {
+		l.mu.Lock()
+		done := l.numStarted == 0
+		l.mu.Unlock()
+		if done {
+			break
+		}
+
 		if err := ctx.Err(); err != nil {
 			return err
 		}
+
+		l.mu.Lock()
 		hook := l.hooks[l.numStarted-1]
+		l.numStarted--
+		if hook.OnStop != nil {
+			l.runningHook = hook
+		}
+		l.mu.Unlock()
+
 		if hook.OnStop == nil {
 			continue
 		}
 
-		l.mu.Lock()
-		l.runningHook = hook
-		l.mu.Unlock()
-
 		runtime, err := l.runStopHook(ctx, hook)
 		if err != nil {
 			// For best-effort cleanup, keep going after errors.
@@ -165,8 +505,9 @@ func (l *Lifecycle) Stop(ctx context.Context) error {
 
 		l.mu.Lock()
 		l.stopRecords = append(l.stopRecords, HookRecord{
-			CallerFrame: hook.callerFrame,
+			CallerFrame: hook.CallerFrame,
 			Func:        hook.OnStop,
+			Module:      hook.Module,
 			Runtime:     runtime,
 		})
 		l.mu.Unlock()
@@ -179,23 +520,96 @@ func (l *Lifecycle) runStopHook(ctx context.Context, hook Hook) (runtime time.Du
 	funcName := fxreflect.FuncName(hook.OnStop)
 
 	l.logger.LogEvent(&fxevent.OnStopExecuting{
-		CallerName:   hook.callerFrame.Function,
+		CallerName:   hook.CallerFrame.Function,
 		FunctionName: funcName,
+		ModuleName:   hook.Module,
 	})
 	defer func() {
 		l.logger.LogEvent(&fxevent.OnStopExecuted{
-			CallerName:   hook.callerFrame.Function,
+			CallerName:   hook.CallerFrame.Function,
 			FunctionName: funcName,
+			ModuleName:   hook.Module,
 			Runtime:      runtime,
 			Err:          err,
 		})
+		l.reportSlowHook("OnStop", funcName, hook, runtime)
 	}()
 
 	begin := l.clock.Now()
-	err = hook.OnStop(ctx)
+	err = l.runHookFunc(ctx, "OnStop", hook.OnStop, hook.OnStopTimeout, hook.CallerFrame)
 	return l.clock.Since(begin), err
 }
 
+// reportSlowHook emits an fxevent.SlowHook event if a hook ran for longer
+// than the slow hook threshold.
+func (l *Lifecycle) reportSlowHook(method, funcName string, hook Hook, runtime time.Duration) {
+	if l.slowHookThreshold <= 0 || runtime <= l.slowHookThreshold {
+		return
+	}
+
+	l.logger.LogEvent(&fxevent.SlowHook{
+		Method:       method,
+		FunctionName: funcName,
+		CallerName:   hook.CallerFrame.Function,
+		Runtime:      runtime,
+		Threshold:    l.slowHookThreshold,
+	})
+}
+
+// runHookFunc calls fn with ctx. If timeout is non-zero, fn receives a
+// context that expires after timeout, and runHookFunc returns an error naming
+// the hook's caller once that happens, even if fn hasn't returned yet.
+func (l *Lifecycle) runHookFunc(
+	ctx context.Context,
+	method string,
+	fn func(context.Context) error,
+	timeout time.Duration,
+	caller fxreflect.Frame,
+) error {
+	if timeout <= 0 {
+		return callHook(ctx, fn)
+	}
+
+	hookCtx, cancel := l.clock.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c := make(chan error, 1)
+	go func() {
+		// If fn calls runtime.Goexit, nothing is written to c by the
+		// last statement, so write here instead.
+		exited := true
+		defer func() {
+			if exited {
+				c <- errHookExited
+			}
+		}()
+
+		err := callHook(hookCtx, fn)
+		exited = false
+		c <- err
+	}()
+
+	select {
+	case err := <-c:
+		return err
+	case <-hookCtx.Done():
+	}
+
+	// If the parent context expired, report that instead of the hook's
+	// own timeout.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%v hook added by %v timed out after %v: %w",
+		method, caller, timeout, hookCtx.Err())
+}
+
+// callHook calls fn with ctx. Its frame marks goroutines that are running a
+// hook, so that StartAppended can tell calls made from hooks apart.
+func callHook(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
 // StartHookRecords returns the info of OnStart hooks that successfully ran till the end,
 // including their caller and runtime. Used to report timeout errors on Start.
 func (l *Lifecycle) StartHookRecords() HookRecords {
@@ -217,17 +631,27 @@ func (l *Lifecycle) StopHookRecords() HookRecords {
 }
 
 // RunningHookCaller returns the name of the hook that was running when a Start/Stop
-// hook timed out.
+// hook timed out. If several hooks were running in parallel, their names are
+// joined with commas.
 func (l *Lifecycle) RunningHookCaller() string {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	return l.runningHook.callerFrame.Function
+	if len(l.runningHooks) > 0 {
+		callers := make([]string, 0, len(l.runningHooks))
+		for _, hook := range l.runningHooks {
+			callers = append(callers, hook.CallerFrame.Function)
+		}
+		sort.Strings(callers)
+		return strings.Join(callers, ", ")
+	}
+	return l.runningHook.CallerFrame.Function
 }
 
 // HookRecord keeps track of each Hook's execution time, the caller that appended the Hook, and function that ran as the Hook.
 type HookRecord struct {
 	CallerFrame fxreflect.Frame             // stack frame of the caller
 	Func        func(context.Context) error // function that ran as sanitized name
+	Module      string                      // module that appended the hook, if any
 	Runtime     time.Duration               // how long the hook ran
 }
 
@@ -247,6 +671,56 @@ func (rs HookRecords) Swap(i, j int) {
 	rs[i], rs[j] = rs[j], rs[i]
 }
 
+// HookRuntimes returns the records as fxevent.HookRuntime values, sorted by
+// runtime in descending order. rs is left unchanged.
+func (rs HookRecords) HookRuntimes() []fxevent.HookRuntime {
+	sorted := make(HookRecords, len(rs))
+	copy(sorted, rs)
+	sort.Stable(sorted)
+
+	runtimes := make([]fxevent.HookRuntime, len(sorted))
+	for i, r := range sorted {
+		runtimes[i] = fxevent.HookRuntime{
+			FunctionName: fxreflect.FuncName(r.Func),
+			CallerName:   r.CallerFrame.Function,
+			ModuleName:   r.Module,
+			Runtime:      r.Runtime,
+		}
+	}
+	return runtimes
+}
+
+// ModuleRuntimes returns the total runtime of the hooks appended by each
+// module, sorted by runtime in descending order. Hooks appended outside of
+// any module aren't included.
+func (rs HookRecords) ModuleRuntimes() []fxevent.ModuleRuntime {
+	var (
+		names    []string
+		runtimes = make(map[string]time.Duration)
+	)
+	for _, r := range rs {
+		if len(r.Module) == 0 {
+			continue
+		}
+		if _, ok := runtimes[r.Module]; !ok {
+			names = append(names, r.Module)
+		}
+		runtimes[r.Module] += r.Runtime
+	}
+
+	modules := make([]fxevent.ModuleRuntime, len(names))
+	for i, name := range names {
+		modules[i] = fxevent.ModuleRuntime{
+			ModuleName: name,
+			Runtime:    runtimes[name],
+		}
+	}
+	sort.SliceStable(modules, func(i, j int) bool {
+		return modules[i].Runtime > modules[j].Runtime
+	})
+	return modules
+}
+
 // Used for logging startup errors.
 func (rs HookRecords) String() string {
 	var b strings.Builder
@@ -26,6 +26,8 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -145,6 +147,24 @@ func TestLifecycleStart(t *testing.T) {
 	})
 }
 
+func TestLifecycleAppendCallerFrame(t *testing.T) {
+	t.Parallel()
+
+	l := New(testLogger(t), fxclock.System)
+	noop := func(context.Context) error { return nil }
+	frame := fxreflect.Frame{Function: "example.com/foo.NewServer", File: "foo.go", Line: 42}
+
+	l.Append(Hook{OnStart: noop})
+	l.Append(Hook{OnStart: noop, CallerFrame: frame})
+	require.NoError(t, l.Start(context.Background()))
+
+	records := l.StartHookRecords()
+	require.Len(t, records, 2)
+	assert.NotEmpty(t, records[0].CallerFrame.Function, "caller of Append must be recorded by default")
+	assert.NotEqual(t, frame, records[0].CallerFrame)
+	assert.Equal(t, frame, records[1].CallerFrame)
+}
+
 func TestLifecycleStop(t *testing.T) {
 	t.Parallel()
 
@@ -152,6 +172,7 @@ func TestLifecycleStop(t *testing.T) {
 		t.Parallel()
 
 		l := New(testLogger(t), fxclock.System)
+		require.NoError(t, l.Start(context.Background()))
 		assert.Nil(t, l.Stop(context.Background()), "no lifecycle hooks should have resulted in stop returning nil")
 	})
 
@@ -319,6 +340,673 @@ func TestLifecycleStop(t *testing.T) {
 	})
 }
 
+func TestLifecycleState(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Transitions", func(t *testing.T) {
+		t.Parallel()
+
+		spy := new(fxlog.Spy)
+		l := New(spy, fxclock.System)
+		var states []State
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				states = append(states, l.State())
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				states = append(states, l.State())
+				return nil
+			},
+		})
+
+		assert.Equal(t, Stopped, l.State())
+		require.NoError(t, l.Start(context.Background()))
+		assert.Equal(t, Started, l.State())
+		require.NoError(t, l.Stop(context.Background()))
+		assert.Equal(t, Stopped, l.State())
+		assert.Equal(t, []State{Starting, Stopping}, states)
+
+		var transitions []string
+		for _, e := range spy.Events().SelectByTypeName("LifecycleStateChanged") {
+			e := e.(*fxevent.LifecycleStateChanged)
+			transitions = append(transitions, e.From+" -> "+e.To)
+		}
+		assert.Equal(t, []string{
+			"stopped -> starting",
+			"starting -> started",
+			"started -> stopping",
+			"stopping -> stopped",
+		}, transitions)
+	})
+
+	t.Run("StartTwice", func(t *testing.T) {
+		t.Parallel()
+
+		l := New(testLogger(t), fxclock.System)
+		var count int
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				count++
+				return nil
+			},
+		})
+
+		require.NoError(t, l.Start(context.Background()))
+		err := l.Start(context.Background())
+		var terr *TransitionError
+		require.ErrorAs(t, err, &terr)
+		assert.Equal(t, "start", terr.Op)
+		assert.Equal(t, Started, terr.State)
+		assert.EqualError(t, err, "cannot start lifecycle: lifecycle is started")
+		assert.Equal(t, 1, count, "OnStart hooks must run only once")
+		assert.Equal(t, Started, l.State())
+	})
+
+	t.Run("OnStarting", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		l := New(testLogger(t), fxclock.System)
+		l.SetOnStarting(func() {
+			assert.Equal(t, Starting, l.State())
+			calls = append(calls, "starting")
+		})
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				calls = append(calls, "start a")
+				return nil
+			},
+		})
+
+		require.NoError(t, l.Start(context.Background()))
+		require.Error(t, l.Start(context.Background()))
+		assert.Equal(t, []string{"starting", "start a"}, calls,
+			"must not be called if Start isn't allowed")
+	})
+
+	t.Run("StopBeforeStart", func(t *testing.T) {
+		t.Parallel()
+
+		l := New(testLogger(t), fxclock.System)
+		err := l.Stop(context.Background())
+		var terr *TransitionError
+		require.ErrorAs(t, err, &terr)
+		assert.Equal(t, "stop", terr.Op)
+		assert.Equal(t, Stopped, terr.State)
+	})
+
+	t.Run("StopAfterFailedStart", func(t *testing.T) {
+		t.Parallel()
+
+		l := New(testLogger(t), fxclock.System)
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				return errors.New("great sadness")
+			},
+		})
+
+		require.Error(t, l.Start(context.Background()))
+		assert.Equal(t, Starting, l.State())
+		require.NoError(t, l.Stop(context.Background()))
+		assert.Equal(t, Stopped, l.State())
+	})
+
+	t.Run("String", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "stopped", Stopped.String())
+		assert.Equal(t, "starting", Starting.String())
+		assert.Equal(t, "started", Started.String())
+		assert.Equal(t, "stopping", Stopping.String())
+		assert.Equal(t, "State(42)", State(42).String())
+	})
+}
+
+func TestLifecycleRestart(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RunsHooksAgain", func(t *testing.T) {
+		t.Parallel()
+
+		l := New(testLogger(t), fxclock.System)
+		var starts, stops int
+		for i := 0; i < 2; i++ {
+			l.Append(Hook{
+				OnStart: func(context.Context) error {
+					starts++
+					return nil
+				},
+				OnStop: func(context.Context) error {
+					stops++
+					return nil
+				},
+			})
+		}
+
+		for i := 1; i <= 3; i++ {
+			require.NoError(t, l.Start(context.Background()))
+			assert.Len(t, l.StartHookRecords(), 2, "start records must be reset")
+			assert.Empty(t, l.StopHookRecords(), "stop records must be reset")
+			require.NoError(t, l.Stop(context.Background()))
+			assert.Len(t, l.StopHookRecords(), 2, "stop records must be reset")
+			assert.Equal(t, 2*i, starts)
+			assert.Equal(t, 2*i, stops)
+		}
+	})
+
+	t.Run("AfterInterruptedStop", func(t *testing.T) {
+		t.Parallel()
+
+		l := New(testLogger(t), fxclock.System)
+		var starts int
+		for i := 0; i < 2; i++ {
+			l.Append(Hook{
+				OnStart: func(context.Context) error {
+					starts++
+					return nil
+				},
+				OnStop: func(context.Context) error {
+					return nil
+				},
+			})
+		}
+
+		require.NoError(t, l.Start(context.Background()))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.Error(t, l.Stop(ctx))
+		assert.Equal(t, Stopped, l.State())
+
+		require.NoError(t, l.Start(context.Background()))
+		assert.Equal(t, 4, starts, "all hooks must start again")
+		assert.Len(t, l.StartHookRecords(), 2)
+	})
+
+	t.Run("AfterFailedStart", func(t *testing.T) {
+		t.Parallel()
+
+		l := New(testLogger(t), fxclock.System)
+		fail := true
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				if fail {
+					return errors.New("great sadness")
+				}
+				return nil
+			},
+		})
+
+		require.Error(t, l.Start(context.Background()))
+		require.NoError(t, l.Stop(context.Background()))
+
+		fail = false
+		require.NoError(t, l.Start(context.Background()))
+		assert.Equal(t, Started, l.State())
+	})
+}
+
+func TestLifecycleAppendAfterStart(t *testing.T) {
+	t.Parallel()
+
+	// record returns a hook that appends name's start and stop to calls.
+	record := func(calls *[]string, name string) Hook {
+		return Hook{
+			OnStart: func(context.Context) error {
+				*calls = append(*calls, "start "+name)
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				*calls = append(*calls, "stop "+name)
+				return nil
+			},
+		}
+	}
+
+	for _, parallel := range []bool{false, true} {
+		parallel := parallel
+		name := "Sequential"
+		if parallel {
+			name = "Parallel"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("WhileStarted", func(t *testing.T) {
+				t.Parallel()
+
+				var calls []string
+				l := New(testLogger(t), fxclock.System)
+				l.SetParallel(parallel)
+				l.Append(record(&calls, "a"))
+				require.NoError(t, l.Start(context.Background()))
+
+				l.Append(record(&calls, "b"))
+				assert.Equal(t, []string{"start a"}, calls,
+					"appended hooks must not start until StartAppended")
+				require.NoError(t, l.StartAppended(context.Background()))
+				assert.Len(t, l.StartHookRecords(), 2)
+
+				require.NoError(t, l.Stop(context.Background()))
+				assert.Equal(t, []string{"start a", "start b", "stop b", "stop a"}, calls)
+			})
+
+			t.Run("WhileStarting", func(t *testing.T) {
+				t.Parallel()
+
+				var calls []string
+				l := New(testLogger(t), fxclock.System)
+				l.SetParallel(parallel)
+				l.Append(Hook{
+					OnStart: func(context.Context) error {
+						calls = append(calls, "start a")
+						l.Append(record(&calls, "b"))
+						return nil
+					},
+				})
+				require.NoError(t, l.Start(context.Background()))
+				require.NoError(t, l.StartAppended(context.Background()))
+				require.NoError(t, l.Stop(context.Background()))
+				assert.Equal(t, []string{"start a", "start b", "stop b"}, calls)
+			})
+
+			t.Run("Nested", func(t *testing.T) {
+				t.Parallel()
+
+				var calls []string
+				l := New(testLogger(t), fxclock.System)
+				l.SetParallel(parallel)
+				require.NoError(t, l.Start(context.Background()))
+
+				l.Append(Hook{
+					OnStart: func(ctx context.Context) error {
+						calls = append(calls, "start a")
+						l.Append(record(&calls, "b"))
+						// Must not wait for the call running this hook.
+						require.NoError(t, l.StartAppended(ctx))
+						assert.Equal(t, []string{"start a"}, calls,
+							"nested hooks must run after the current hook")
+						return nil
+					},
+				})
+				require.NoError(t, l.StartAppended(context.Background()))
+				assert.Equal(t, []string{"start a", "start b"}, calls)
+
+				l.Append(record(&calls, "c"))
+				require.NoError(t, l.StartAppended(context.Background()))
+				require.NoError(t, l.Stop(context.Background()))
+				assert.Equal(t, []string{"start a", "start b", "start c", "stop c", "stop b"}, calls)
+			})
+
+			t.Run("Concurrent", func(t *testing.T) {
+				t.Parallel()
+
+				l := New(testLogger(t), fxclock.System)
+				l.SetParallel(parallel)
+				require.NoError(t, l.Start(context.Background()))
+
+				running, release := make(chan struct{}), make(chan struct{})
+				l.Append(Hook{
+					OnStart: func(context.Context) error {
+						close(running)
+						<-release
+						return nil
+					},
+				})
+				first := make(chan error)
+				go func() { first <- l.StartAppended(context.Background()) }()
+				<-running
+
+				// A call from another goroutine must wait for its own
+				// hooks, and report their errors.
+				l.Append(Hook{
+					OnStart: func(context.Context) error {
+						return errors.New("great sadness")
+					},
+				})
+				second := make(chan error)
+				go func() { second <- l.StartAppended(context.Background()) }()
+
+				select {
+				case err := <-second:
+					t.Fatalf("StartAppended returned before its hooks ran: %v", err)
+				case <-time.After(10 * time.Millisecond):
+				}
+
+				close(release)
+				assert.NoError(t, <-first)
+				assert.EqualError(t, <-second, "great sadness")
+				require.NoError(t, l.Stop(context.Background()))
+			})
+
+			t.Run("FailedHookIsDropped", func(t *testing.T) {
+				t.Parallel()
+
+				var calls []string
+				l := New(testLogger(t), fxclock.System)
+				l.SetParallel(parallel)
+				require.NoError(t, l.Start(context.Background()))
+
+				l.Append(Hook{
+					OnStart: func(context.Context) error {
+						return errors.New("great sadness")
+					},
+					OnStop: func(context.Context) error {
+						assert.Fail(t, "OnStop of a failed hook must not run")
+						return nil
+					},
+				})
+				require.Error(t, l.StartAppended(context.Background()))
+
+				l.Append(record(&calls, "b"))
+				require.NoError(t, l.StartAppended(context.Background()))
+				require.NoError(t, l.Stop(context.Background()))
+				assert.Equal(t, []string{"start b", "stop b"}, calls)
+			})
+		})
+	}
+
+	t.Run("NotStarted", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		l := New(testLogger(t), fxclock.System)
+		l.Append(record(&calls, "a"))
+		require.NoError(t, l.StartAppended(context.Background()))
+		assert.Empty(t, calls, "StartAppended must do nothing before Start")
+
+		require.NoError(t, l.Start(context.Background()))
+		assert.Equal(t, []string{"start a"}, calls)
+	})
+}
+
+func TestLifecycleHookTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OnStart", func(t *testing.T) {
+		t.Parallel()
+
+		l := New(testLogger(t), fxclock.System)
+		l.Append(Hook{
+			OnStart: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			OnStartTimeout: time.Millisecond,
+			OnStop: func(context.Context) error {
+				assert.Fail(t, "this hook should not run")
+				return nil
+			},
+		})
+
+		err := l.Start(context.Background())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "OnStart hook added by")
+		assert.Contains(t, err.Error(), "timed out after 1ms")
+		assert.NoError(t, l.Stop(context.Background()))
+	})
+
+	t.Run("OnStop", func(t *testing.T) {
+		t.Parallel()
+
+		l := New(testLogger(t), fxclock.System)
+		l.Append(Hook{
+			OnStop: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			OnStopTimeout: time.Millisecond,
+		})
+
+		var stopped bool
+		l.Append(Hook{
+			OnStop: func(ctx context.Context) error {
+				stopped = true
+				return nil
+			},
+		})
+
+		require.NoError(t, l.Start(context.Background()))
+		err := l.Stop(context.Background())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "OnStop hook added by")
+		assert.True(t, stopped, "stop hooks must run despite the timeout")
+	})
+
+	t.Run("FinishesInTime", func(t *testing.T) {
+		t.Parallel()
+
+		l := New(testLogger(t), fxclock.System)
+		l.Append(Hook{
+			OnStart: func(ctx context.Context) error {
+				_, ok := ctx.Deadline()
+				assert.True(t, ok, "hook context must have a deadline")
+				return nil
+			},
+			OnStartTimeout: time.Minute,
+		})
+
+		assert.NoError(t, l.Start(context.Background()))
+		assert.NoError(t, l.Stop(context.Background()))
+	})
+
+	t.Run("ParentContextExpired", func(t *testing.T) {
+		t.Parallel()
+
+		l := New(testLogger(t), fxclock.System)
+		ctx, cancel := context.WithCancel(context.Background())
+		l.Append(Hook{
+			OnStart: func(ctx context.Context) error {
+				cancel()
+				<-ctx.Done()
+				return nil
+			},
+			OnStartTimeout: time.Minute,
+		})
+
+		err := l.Start(ctx)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotContains(t, err.Error(), "timed out")
+	})
+}
+
+func TestLifecycleParallel(t *testing.T) {
+	t.Parallel()
+
+	newParallel := func(t *testing.T) *Lifecycle {
+		l := New(testLogger(t), fxclock.System)
+		l.SetParallel(true)
+		return l
+	}
+
+	// Builds an OnStart hook that waits for all n hooks built with the same
+	// call to start. It fails if the hooks don't run concurrently.
+	rendezvous := func(n int) func() func(context.Context) error {
+		var wg sync.WaitGroup
+		wg.Add(n)
+		return func() func(context.Context) error {
+			return func(context.Context) error {
+				wg.Done()
+				done := make(chan struct{})
+				go func() {
+					wg.Wait()
+					close(done)
+				}()
+				select {
+				case <-done:
+					return nil
+				case <-time.After(5 * time.Second):
+					return errors.New("hooks did not run concurrently")
+				}
+			}
+		}
+	}
+
+	t.Run("RunsIndependentHooksConcurrently", func(t *testing.T) {
+		t.Parallel()
+
+		l := newParallel(t)
+		hook := rendezvous(2)
+		l.Append(Hook{OnStart: hook(), Owner: NewOwner()})
+		l.Append(Hook{OnStart: hook(), Owner: NewOwner()})
+
+		require.NoError(t, l.Start(context.Background()))
+		assert.Len(t, l.StartHookRecords(), 2)
+		require.NoError(t, l.Stop(context.Background()))
+	})
+
+	t.Run("OrdersDependentHooks", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu    sync.Mutex
+			calls []string
+		)
+		record := func(name string) func(context.Context) error {
+			return func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, name)
+				return nil
+			}
+		}
+
+		a := NewOwner()
+		b := NewOwner(a)
+		c := NewOwner(b)
+
+		l := newParallel(t)
+		l.Append(Hook{OnStart: record("start a"), OnStop: record("stop a"), Owner: a})
+		l.Append(Hook{OnStart: record("start b"), OnStop: record("stop b"), Owner: b})
+		l.Append(Hook{OnStart: record("start c"), OnStop: record("stop c"), Owner: c})
+
+		require.NoError(t, l.Start(context.Background()))
+		require.NoError(t, l.Stop(context.Background()))
+		assert.Equal(t, []string{
+			"start a", "start b", "start c",
+			"stop c", "stop b", "stop a",
+		}, calls)
+	})
+
+	t.Run("HooksWithoutOwnerAreBarriers", func(t *testing.T) {
+		t.Parallel()
+
+		var count int32
+		l := newParallel(t)
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				atomic.AddInt32(&count, 1)
+				return nil
+			},
+			Owner: NewOwner(),
+		})
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				assert.Equal(t, int32(1), atomic.LoadInt32(&count),
+					"hook without an owner must run after earlier hooks")
+				atomic.AddInt32(&count, 1)
+				return nil
+			},
+		})
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				assert.Equal(t, int32(2), atomic.LoadInt32(&count),
+					"hook must run after earlier hooks without an owner")
+				return nil
+			},
+			Owner: NewOwner(),
+		})
+
+		require.NoError(t, l.Start(context.Background()))
+	})
+
+	t.Run("ErrHaltsDependentsAndRollsBack", func(t *testing.T) {
+		t.Parallel()
+
+		err := errors.New("a starter error")
+		a := NewOwner()
+		b := NewOwner(a)
+		stopped := false
+
+		l := newParallel(t)
+		l.Append(Hook{
+			OnStart: func(context.Context) error { return nil },
+			OnStop: func(context.Context) error {
+				stopped = true
+				return nil
+			},
+			Owner: a,
+		})
+		l.Append(Hook{
+			OnStart: func(context.Context) error { return err },
+			OnStop: func(context.Context) error {
+				t.Error("this stopper shouldn't run, since its starter failed")
+				return nil
+			},
+			Owner: b,
+		})
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				t.Error("this starter shouldn't run, since its dependency failed")
+				return nil
+			},
+			Owner: NewOwner(b),
+		})
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				t.Error("this starter shouldn't run, since a previous hook failed")
+				return nil
+			},
+		})
+
+		assert.ErrorIs(t, l.Start(context.Background()), err)
+		assert.NoError(t, l.Stop(context.Background()))
+		assert.True(t, stopped, "hook that started must be stopped")
+	})
+
+	t.Run("StopGathersAllErrs", func(t *testing.T) {
+		t.Parallel()
+
+		err := errors.New("some stop error")
+		err2 := errors.New("some other stop error")
+
+		l := newParallel(t)
+		l.Append(Hook{OnStop: func(context.Context) error { return err }, Owner: NewOwner()})
+		l.Append(Hook{OnStop: func(context.Context) error { return err2 }, Owner: NewOwner()})
+
+		require.NoError(t, l.Start(context.Background()))
+		stopErr := l.Stop(context.Background())
+		assert.ErrorIs(t, stopErr, err)
+		assert.ErrorIs(t, stopErr, err2)
+		assert.Len(t, l.StopHookRecords(), 2)
+
+		// Hooks are stopped only once.
+		var terr *TransitionError
+		assert.ErrorAs(t, l.Stop(context.Background()), &terr)
+	})
+
+	t.Run("DoNotRunStartHooksWithExpiredCtx", func(t *testing.T) {
+		t.Parallel()
+
+		l := newParallel(t)
+		l.Append(Hook{
+			OnStart: func(context.Context) error {
+				assert.Fail(t, "this hook should not run")
+				return nil
+			},
+			Owner: NewOwner(),
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, l.Start(ctx), context.Canceled)
+		require.NoError(t, l.Stop(context.Background()))
+	})
+}
+
 func TestHookRecordsFormat(t *testing.T) {
 	t.Parallel()
 
@@ -362,6 +1050,28 @@ func TestHookRecordsFormat(t *testing.T) {
 			assert.Contains(t, s, "somefunc.go:1", "file name and line should be reported")
 		}
 	})
+
+	t.Run("ModuleRuntimes", func(t *testing.T) {
+		t.Parallel()
+
+		noop := func(context.Context) error { return nil }
+		r := HookRecords{
+			{Func: noop, Module: "a", Runtime: 10 * time.Millisecond},
+			{Func: noop, Runtime: 100 * time.Millisecond},
+			{Func: noop, Module: "b", Runtime: 15 * time.Millisecond},
+			{Func: noop, Module: "a", Runtime: 10 * time.Millisecond},
+		}
+
+		assert.Equal(t, []fxevent.ModuleRuntime{
+			{ModuleName: "a", Runtime: 20 * time.Millisecond},
+			{ModuleName: "b", Runtime: 15 * time.Millisecond},
+		}, r.ModuleRuntimes())
+
+		runtimes := r.HookRuntimes()
+		require.Len(t, runtimes, 4)
+		assert.Equal(t, "", runtimes[0].ModuleName)
+		assert.Equal(t, "b", runtimes[1].ModuleName)
+	})
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,251 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package lifecycle
+
+import (
+	"context"
+
+	"go.uber.org/multierr"
+)
+
+// mustPrecede reports whether hook a, which was appended before hook b, must
+// start before b starts and stop after b stops.
+func mustPrecede(a, b Hook) bool {
+	if a.Owner == nil || b.Owner == nil || a.Owner == b.Owner {
+		return true
+	}
+	return b.Owner.dependsOn(a.Owner) || a.Owner.dependsOn(b.Owner)
+}
+
+// startParallel runs OnStart hooks concurrently, starting each hook only
+// after all hooks it depends on have started successfully. After the first
+// failure, no new hooks are started.
+func (l *Lifecycle) startParallel(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := l.hooks
+	l.mu.Unlock()
+
+	preds := make([][]int, len(hooks))
+	for j := range hooks {
+		for i := 0; i < j; i++ {
+			if mustPrecede(hooks[i], hooks[j]) {
+				preds[j] = append(preds[j], i)
+			}
+		}
+	}
+
+	l.mu.Lock()
+	l.started = make([]bool, len(hooks))
+	l.mu.Unlock()
+
+	err := l.runGraph(ctx, preds, true /* haltOnError */, func(i int) error {
+		hook := hooks[i]
+		if hook.OnStart != nil {
+			l.setRunning(i, hook)
+			runtime, err := l.runStartHook(ctx, hook)
+			l.clearRunning(i)
+			if err != nil {
+				return err
+			}
+
+			l.mu.Lock()
+			l.startRecords = append(l.startRecords, HookRecord{
+				CallerFrame: hook.CallerFrame,
+				Func:        hook.OnStart,
+				Module:      hook.Module,
+				Runtime:     runtime,
+			})
+			l.mu.Unlock()
+		}
+
+		l.mu.Lock()
+		l.started[i] = true
+		l.mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// All hooks that existed when Start began have started. Hooks
+	// appended since then are started in order by the caller.
+	l.mu.Lock()
+	l.numStarted = len(hooks)
+	l.mu.Unlock()
+	return nil
+}
+
+// stopParallel runs OnStop hooks of started hooks concurrently, stopping each
+// hook only after all hooks that depend on it have stopped. Errors don't
+// prevent other hooks from stopping.
+func (l *Lifecycle) stopParallel(ctx context.Context) error {
+	l.mu.Lock()
+	var started []int // indexes into l.hooks
+	for i, ok := range l.started {
+		if ok {
+			started = append(started, i)
+		}
+	}
+	hooks := l.hooks
+	l.mu.Unlock()
+	preds := make([][]int, len(started))
+	for j := range started {
+		for i := j + 1; i < len(started); i++ {
+			if mustPrecede(hooks[started[j]], hooks[started[i]]) {
+				preds[j] = append(preds[j], i)
+			}
+		}
+	}
+
+	return l.runGraph(ctx, preds, false /* haltOnError */, func(n int) (err error) {
+		i := started[n]
+		hook := hooks[i]
+		defer func() {
+			l.mu.Lock()
+			l.started[i] = false
+			l.mu.Unlock()
+		}()
+
+		if hook.OnStop == nil {
+			return nil
+		}
+
+		l.setRunning(i, hook)
+		runtime, err := l.runStopHook(ctx, hook)
+		l.clearRunning(i)
+
+		l.mu.Lock()
+		l.stopRecords = append(l.stopRecords, HookRecord{
+			CallerFrame: hook.CallerFrame,
+			Func:        hook.OnStop,
+			Module:      hook.Module,
+			Runtime:     runtime,
+		})
+		l.mu.Unlock()
+		return err
+	})
+}
+
+func (l *Lifecycle) setRunning(i int, hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.runningHooks == nil {
+		l.runningHooks = make(map[int]Hook)
+	}
+	l.runningHooks[i] = hook
+}
+
+func (l *Lifecycle) clearRunning(i int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.runningHooks, i)
+}
+
+type graphResult struct {
+	node int
+	err  error
+}
+
+// runGraph calls run for every node of a dependency graph, where preds[i]
+// lists the nodes that must complete before node i runs. Nodes whose
+// predecessors have all completed run concurrently.
+//
+// Once the context expires, or a node fails and haltOnError is set, no new
+// nodes are run. runGraph always waits for running nodes to finish, and
+// returns the errors of all failed nodes.
+func (l *Lifecycle) runGraph(
+	ctx context.Context,
+	preds [][]int,
+	haltOnError bool,
+	run func(int) error,
+) error {
+	succs := make([][]int, len(preds))
+	pending := make([]int, len(preds))
+	var ready []int
+	for i, ps := range preds {
+		pending[i] = len(ps)
+		for _, p := range ps {
+			succs[p] = append(succs[p], i)
+		}
+		if len(ps) == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	results := make(chan graphResult, len(preds))
+	var (
+		errs    []error
+		halted  bool
+		running int
+	)
+	for len(ready) > 0 || running > 0 {
+		if !halted {
+			if err := ctx.Err(); err != nil {
+				errs = append(errs, err)
+				halted = true
+			}
+		}
+
+		if !halted {
+			for _, i := range ready {
+				running++
+				go func(i int) {
+					// If the hook calls runtime.Goexit, report it
+					// rather than hang forever.
+					exited := true
+					defer func() {
+						if exited {
+							results <- graphResult{node: i, err: errHookExited}
+						}
+					}()
+
+					err := run(i)
+					exited = false
+					results <- graphResult{node: i, err: err}
+				}(i)
+			}
+		}
+		ready = ready[:0]
+
+		if running == 0 {
+			break
+		}
+
+		r := <-results
+		running--
+		if r.err != nil {
+			errs = append(errs, r.err)
+			if haltOnError {
+				halted = true
+				continue
+			}
+		}
+
+		for _, s := range succs[r.node] {
+			pending[s]--
+			if pending[s] == 0 {
+				ready = append(ready, s)
+			}
+		}
+	}
+
+	return multierr.Combine(errs...)
+}
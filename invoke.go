@@ -82,8 +82,11 @@ func runInvoke(c container, i invoke) error {
 			"not to fx.Invoke: fx.Invoke received %v from:\n%+v",
 			fn, i.Stack)
 
+	case annotationError:
+		return fn.report("fx.Invoke", i.Stack)
+
 	case annotated:
-		af, err := fn.Build()
+		af, err := fn.build("fx.Invoke", i.Stack)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,172 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"go.uber.org/fx/internal/fxreflect"
+)
+
+// Lazy wraps a constructor passed to Provide so that it runs only when its
+// result is first needed, rather than while the application is built.
+//
+// Instead of the constructor's result T, the application provides a function
+// with the signature,
+//
+//	func() (T, error)
+//
+// The first call to this function builds the constructor's dependencies and
+// runs the constructor. Later calls return the same result. For example,
+//
+//	fx.Provide(fx.Lazy(newReportingClient))
+//
+//	func newHandler(getClient func() (*ReportingClient, error)) *Handler {
+//	  return &Handler{getClient: getClient}
+//	}
+//
+//	func (h *Handler) ServeReport(w http.ResponseWriter, r *http.Request) {
+//	  client, err := h.getClient()
+//	  // ...
+//	}
+//
+// The constructor may return an error as its second result, and must not be
+// annotated. Its dependencies are resolved from the module it's provided to,
+// so missing dependencies are reported by the first call rather than by New.
+//
+// Lifecycle hooks appended by the constructor behave like any other hooks.
+// If the application has already started, their OnStart hooks run before the
+// function returns, limited by the StartTimeout, and their OnStop hooks run
+// when the application stops. If an OnStart hook fails, the function returns
+// its error. If the function is called from an OnStart hook, the hooks it
+// appends run after that hook returns instead.
+//
+// The function may be called by other constructors, including those built
+// for another lazy constructor or for App.Resolve, and by hooks. Such calls
+// must be made on the goroutine running the constructor or hook: one that
+// waits for another goroutine to call a lazy function never returns.
+func Lazy(constructor interface{}) interface{} {
+	return lazyConstructor{
+		Target: constructor,
+		Stack:  fxreflect.CallerStack(1, 0),
+	}
+}
+
+type lazyConstructor struct {
+	Target interface{}
+	Stack  fxreflect.Stack
+}
+
+func (l lazyConstructor) String() string {
+	return fmt.Sprintf("fx.Lazy(%v)", fxreflect.FuncName(l.Target))
+}
+
+// lazyProvider builds a constructor that provides a function that runs l
+// inside this module the first time it's called.
+func (m *module) lazyProvider(l lazyConstructor) (interface{}, error) {
+	ft := reflect.TypeOf(l.Target)
+	if ft == nil || ft.Kind() != reflect.Func {
+		return nil, fmt.Errorf("fx.Lazy requires a function, got %v (%T)", l.Target, l.Target)
+	}
+
+	switch {
+	case ft.NumOut() == 1 && ft.Out(0) != _typeOfError:
+	case ft.NumOut() == 2 && ft.Out(0) != _typeOfError && ft.Out(1) == _typeOfError:
+	default:
+		return nil, fmt.Errorf("fx.Lazy requires a function that returns a value, "+
+			"or a value and an error, got %v", ft)
+	}
+
+	var (
+		once  sync.Once
+		value reflect.Value
+		err   error
+	)
+	resultType := ft.Out(0)
+	getterType := reflect.FuncOf(nil, []reflect.Type{resultType, _typeOfError}, false)
+	getter := reflect.MakeFunc(getterType, func([]reflect.Value) []reflect.Value {
+		once.Do(func() {
+			value, err = m.runLazy(l)
+		})
+		if err != nil {
+			return []reflect.Value{reflect.Zero(resultType), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{value, _nilError}
+	})
+
+	providerType := reflect.FuncOf(nil, []reflect.Type{getterType}, false)
+	provider := reflect.MakeFunc(providerType, func([]reflect.Value) []reflect.Value {
+		return []reflect.Value{getter}
+	})
+	return provider.Interface(), nil
+}
+
+// runLazy builds the dependencies of l's constructor, calls it, and starts
+// any lifecycle hooks it appended if the application is running.
+func (m *module) runLazy(l lazyConstructor) (_ reflect.Value, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("%v from:\n%+vFailed: %v", l, l.Stack, err)
+		}
+	}()
+
+	ft := reflect.TypeOf(l.Target)
+	ins := make([]reflect.Type, ft.NumIn())
+	for i := range ins {
+		ins[i] = ft.In(i)
+	}
+
+	// Only resolve the dependencies while holding the container. The
+	// constructor itself may call other lazy functions from any goroutine.
+	var args []reflect.Value
+	resolve := reflect.MakeFunc(reflect.FuncOf(ins, nil, ft.IsVariadic()),
+		func(in []reflect.Value) []reflect.Value {
+			args = in
+			return nil
+		})
+	err = m.app.withContainer(func() error {
+		return m.scope.Invoke(resolve.Interface())
+	})
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	fv := reflect.ValueOf(l.Target)
+	var results []reflect.Value
+	if ft.IsVariadic() {
+		results = fv.CallSlice(args)
+	} else {
+		results = fv.Call(args)
+	}
+	if len(results) == 2 && !results[1].IsNil() {
+		return reflect.Value{}, results[1].Interface().(error)
+	}
+
+	ctx, cancel := m.app.clock.WithTimeout(context.Background(), m.app.StartTimeout())
+	defer cancel()
+	if err := m.app.lifecycle.StartAppended(ctx); err != nil {
+		return reflect.Value{}, err
+	}
+	return results[0], nil
+}
@@ -0,0 +1,396 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestLazySuccess(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ Name string }
+	type Client struct{ Name string }
+
+	t.Run("constructor runs on first call", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		var getClient func() (*Client, error)
+		app := fxtest.New(t,
+			fx.Provide(
+				func() *Config {
+					calls = append(calls, "config")
+					return &Config{Name: "client"}
+				},
+				fx.Lazy(func(cfg *Config) *Client {
+					calls = append(calls, "client")
+					return &Client{Name: cfg.Name}
+				}),
+			),
+			fx.Populate(&getClient),
+		)
+		defer app.RequireStart().RequireStop()
+		assert.Empty(t, calls, "nothing must be built before the first call")
+
+		c1, err := getClient()
+		require.NoError(t, err)
+		assert.Equal(t, "client", c1.Name)
+
+		c2, err := getClient()
+		require.NoError(t, err)
+		assert.Same(t, c1, c2)
+		assert.Equal(t, []string{"config", "client"}, calls)
+	})
+
+	t.Run("hooks appended after start", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		var getClient func() (*Client, error)
+		app := fxtest.New(t,
+			fx.Provide(fx.Lazy(func(lc fx.Lifecycle) (*Client, error) {
+				lc.Append(fx.Hook{
+					OnStart: func(context.Context) error {
+						calls = append(calls, "start")
+						return nil
+					},
+					OnStop: func(context.Context) error {
+						calls = append(calls, "stop")
+						return nil
+					},
+				})
+				return &Client{}, nil
+			})),
+			fx.Populate(&getClient),
+		)
+		app.RequireStart()
+
+		_, err := getClient()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"start"}, calls, "OnStart must run before the call returns")
+
+		app.RequireStop()
+		assert.Equal(t, []string{"start", "stop"}, calls)
+
+		app.RequireStart().RequireStop()
+		assert.Equal(t, []string{"start", "stop", "start", "stop"}, calls,
+			"hooks must run again on restart")
+	})
+
+	t.Run("called from an OnStart hook", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		app := fxtest.New(t,
+			fx.Provide(fx.Lazy(func(lc fx.Lifecycle) *Client {
+				lc.Append(fx.Hook{
+					OnStart: func(context.Context) error {
+						calls = append(calls, "client")
+						return nil
+					},
+				})
+				return &Client{}
+			})),
+			fx.Invoke(func(lc fx.Lifecycle, getClient func() (*Client, error)) {
+				lc.Append(fx.Hook{
+					OnStart: func(context.Context) error {
+						_, err := getClient()
+						calls = append(calls, "invoke")
+						return err
+					},
+				})
+			}),
+		)
+		defer app.RequireStart().RequireStop()
+		assert.Equal(t, []string{"invoke", "client"}, calls)
+	})
+
+	t.Run("called from another lazy OnStart hook", func(t *testing.T) {
+		t.Parallel()
+
+		type Server struct{}
+
+		var calls []string
+		var getServer func() (*Server, error)
+		app := fxtest.New(t,
+			fx.Provide(
+				fx.Lazy(func(lc fx.Lifecycle) *Client {
+					lc.Append(fx.StartHook(func() {
+						calls = append(calls, "client")
+					}))
+					return &Client{}
+				}),
+				fx.Lazy(func(lc fx.Lifecycle, getClient func() (*Client, error)) *Server {
+					lc.Append(fx.StartHook(func() error {
+						_, err := getClient()
+						calls = append(calls, "server")
+						return err
+					}))
+					return &Server{}
+				}),
+			),
+			fx.Populate(&getServer),
+		)
+		defer app.RequireStart().RequireStop()
+
+		_, err := getServer()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"server", "client"}, calls)
+	})
+
+	t.Run("called concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		type Server struct{}
+
+		running, release := make(chan struct{}), make(chan struct{})
+		var (
+			getClient func() (*Client, error)
+			getServer func() (*Server, error)
+		)
+		app := fxtest.New(t,
+			fx.Provide(
+				fx.Lazy(func(lc fx.Lifecycle) *Client {
+					lc.Append(fx.StartHook(func() {
+						close(running)
+						<-release
+					}))
+					return &Client{}
+				}),
+				fx.Lazy(func(lc fx.Lifecycle) *Server {
+					lc.Append(fx.StartHook(func() error {
+						return errors.New("great sadness")
+					}))
+					return &Server{}
+				}),
+			),
+			fx.Populate(&getClient, &getServer),
+		)
+		defer app.RequireStart().RequireStop()
+
+		clientErr := make(chan error)
+		go func() {
+			_, err := getClient()
+			clientErr <- err
+		}()
+		<-running
+
+		serverErr := make(chan error)
+		go func() {
+			_, err := getServer()
+			serverErr <- err
+		}()
+
+		select {
+		case err := <-serverErr:
+			t.Fatalf("must not return before its OnStart hook runs: %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		close(release)
+		assert.NoError(t, <-clientErr)
+		assert.ErrorContains(t, <-serverErr, "great sadness")
+	})
+
+	t.Run("called by a dependency", func(t *testing.T) {
+		t.Parallel()
+
+		type Server struct{ Client *Client }
+		type Handler struct{ Client *Client }
+
+		var getServer func() (*Server, error)
+		app := fxtest.New(t,
+			fx.Provide(
+				fx.Lazy(func() *Client { return &Client{Name: "client"} }),
+				func(getClient func() (*Client, error)) (*Handler, error) {
+					c, err := getClient()
+					return &Handler{Client: c}, err
+				},
+				fx.Lazy(func(h *Handler) *Server {
+					return &Server{Client: h.Client}
+				}),
+			),
+			fx.Populate(&getServer),
+		)
+		defer app.RequireStart().RequireStop()
+
+		s, err := getServer()
+		require.NoError(t, err)
+		assert.Equal(t, "client", s.Client.Name)
+	})
+
+	t.Run("called from another goroutine by a dependency", func(t *testing.T) {
+		t.Parallel()
+
+		type Server struct{}
+		type Handler struct{}
+
+		clientErr := make(chan error, 1)
+		var getServer func() (*Server, error)
+		app := fxtest.New(t,
+			fx.Provide(
+				fx.Lazy(func() *Client { return &Client{} }),
+				func(getClient func() (*Client, error)) *Handler {
+					go func() {
+						_, err := getClient()
+						clientErr <- err
+					}()
+
+					// The container is in use by this goroutine, so the
+					// other one can't use it until the constructor
+					// returns.
+					select {
+					case <-clientErr:
+						assert.Fail(t, "lazy function must wait for the container")
+					case <-time.After(10 * time.Millisecond):
+					}
+					return &Handler{}
+				},
+				fx.Lazy(func(*Handler) *Server { return &Server{} }),
+			),
+			fx.Populate(&getServer),
+		)
+		defer app.RequireStart().RequireStop()
+
+		_, err := getServer()
+		require.NoError(t, err)
+		assert.NoError(t, <-clientErr)
+	})
+}
+
+func TestLazyFailure(t *testing.T) {
+	t.Parallel()
+
+	type Client struct{}
+
+	t.Run("constructor error is returned on every call", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		var getClient func() (*Client, error)
+		app := fxtest.New(t,
+			fx.Provide(fx.Lazy(func() (*Client, error) {
+				calls++
+				return nil, errors.New("great sadness")
+			})),
+			fx.Populate(&getClient),
+		)
+		defer app.RequireStart().RequireStop()
+
+		for i := 0; i < 2; i++ {
+			c, err := getClient()
+			require.Error(t, err)
+			assert.Nil(t, c)
+			assert.Contains(t, err.Error(), "great sadness")
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		t.Parallel()
+
+		type Missing struct{}
+		var getClient func() (*Client, error)
+		app := fxtest.New(t,
+			fx.Provide(fx.Lazy(func(*Missing) *Client { return &Client{} })),
+			fx.Populate(&getClient),
+		)
+		defer app.RequireStart().RequireStop()
+
+		_, err := getClient()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fx.Lazy(")
+		assert.Contains(t, err.Error(), "missing type: *fx_test.Missing")
+	})
+
+	t.Run("OnStart hook fails", func(t *testing.T) {
+		t.Parallel()
+
+		var getClient func() (*Client, error)
+		app := fxtest.New(t,
+			fx.Provide(fx.Lazy(func(lc fx.Lifecycle) *Client {
+				lc.Append(fx.Hook{
+					OnStart: func(context.Context) error {
+						return errors.New("great sadness")
+					},
+					OnStop: func(context.Context) error {
+						assert.Fail(t, "OnStop of a failed hook must not run")
+						return nil
+					},
+				})
+				return &Client{}
+			})),
+			fx.Populate(&getClient),
+		)
+		defer app.RequireStart().RequireStop()
+
+		_, err := getClient()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("invalid constructors", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			desc string
+			give interface{}
+			want string
+		}{
+			{
+				desc: "not a function",
+				give: &Client{},
+				want: "fx.Lazy requires a function",
+			},
+			{
+				desc: "no results",
+				give: func() {},
+				want: "fx.Lazy requires a function that returns a value",
+			},
+			{
+				desc: "only an error",
+				give: func() error { return nil },
+				want: "fx.Lazy requires a function that returns a value",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.desc, func(t *testing.T) {
+				t.Parallel()
+
+				app := NewForTest(t, fx.Provide(fx.Lazy(tt.give)))
+				err := app.Err()
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.want)
+			})
+		}
+	})
+}
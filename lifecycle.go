@@ -22,13 +22,24 @@ package fx
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
 
+	"go.uber.org/fx/internal/fxreflect"
 	"go.uber.org/fx/internal/lifecycle"
 )
 
 // Lifecycle allows constructors to register callbacks that are executed on
 // application start and stop. See the documentation for App for details on Fx
 // applications' initialization, startup, and shutdown logic.
+//
+// Each fx.Module has a Lifecycle of its own. Hooks appended to it run as part
+// of the application's lifecycle, in the same order as any other hooks, but
+// are tagged with the name of the module: lifecycle events name the module
+// that appended each hook, and the fxevent.HookRuntimes event reports the
+// total runtime of each module's hooks.
 type Lifecycle interface {
 	Append(Hook)
 }
@@ -37,18 +48,196 @@ type Lifecycle interface {
 // If a Hook's OnStart callback isn't executed (because a previous OnStart
 // failure short-circuited application startup), its OnStop callback won't be
 // executed.
+//
+// OnStartTimeout and OnStopTimeout optionally limit how long each callback
+// may run. The callback receives a context that expires after the given
+// duration, in addition to the application's start or stop deadline. If the
+// callback doesn't return in time, the application stops waiting for it and
+// fails with an error naming the function that appended the Hook.
 type Hook struct {
 	OnStart func(context.Context) error
 	OnStop  func(context.Context) error
+
+	OnStartTimeout time.Duration
+	OnStopTimeout  time.Duration
+
+	// Location reported for hooks that Fx appends on behalf of a
+	// constructor. If unset, the caller of Append is reported.
+	callerFrame fxreflect.Frame
+}
+
+// StartHook returns a Hook that runs the given function on start. The
+// function may have any of the following signatures,
+//
+//	func()
+//	func() error
+//	func(context.Context)
+//	func(context.Context) error
+//
+// Functions that don't accept a context.Context aren't interrupted when the
+// application's start deadline expires. For example,
+//
+//	lc.Append(fx.StartHook(server.Start))
+//
+// StartHook panics if the function has a different signature.
+func StartHook(start interface{}) Hook {
+	return Hook{OnStart: mustHookFunc("StartHook", start)}
+}
+
+// StopHook returns a Hook that runs the given function on stop. It accepts
+// the same function signatures as StartHook.
+//
+//	lc.Append(fx.StopHook(conn.Close))
+//
+// StopHook panics if the function has a different signature.
+func StopHook(stop interface{}) Hook {
+	return Hook{OnStop: mustHookFunc("StopHook", stop)}
+}
+
+// StartStopHook returns a Hook that runs the given functions on start and
+// stop. Both accept the same function signatures as StartHook.
+//
+//	lc.Append(fx.StartStopHook(server.Start, server.Shutdown))
+//
+// StartStopHook panics if either function has a different signature.
+func StartStopHook(start, stop interface{}) Hook {
+	return Hook{
+		OnStart: mustHookFunc("StartStopHook", start),
+		OnStop:  mustHookFunc("StartStopHook", stop),
+	}
 }
 
-type lifecycleWrapper struct{ *lifecycle.Lifecycle }
+var _hookFuncTypes = []reflect.Type{
+	reflect.TypeOf((func(context.Context) error)(nil)),
+	reflect.TypeOf((func(context.Context))(nil)),
+	reflect.TypeOf((func() error)(nil)),
+	reflect.TypeOf((func())(nil)),
+}
+
+func mustHookFunc(name string, fn interface{}) func(context.Context) error {
+	f, err := hookFunc(fn)
+	if err != nil {
+		panic(fmt.Sprintf("fx.%v: %v", name, err))
+	}
+	return f
+}
+
+// hookFunc adapts fn, which must have one of the signatures accepted by
+// StartHook, to func(context.Context) error. It returns nil for nil
+// functions.
+func hookFunc(fn interface{}) (func(context.Context) error, error) {
+	switch fn := fn.(type) {
+	case nil:
+		return nil, nil
+	case func(context.Context) error:
+		return fn, nil
+	case func(context.Context):
+		if fn == nil {
+			return nil, nil
+		}
+		return func(ctx context.Context) error {
+			fn(ctx)
+			return nil
+		}, nil
+	case func() error:
+		if fn == nil {
+			return nil, nil
+		}
+		return func(context.Context) error {
+			return fn()
+		}, nil
+	case func():
+		if fn == nil {
+			return nil, nil
+		}
+		return func(context.Context) error {
+			fn()
+			return nil
+		}, nil
+	}
+
+	// Named function types, such as http.HandlerFunc.
+	v := reflect.ValueOf(fn)
+	for _, t := range _hookFuncTypes {
+		if v.Kind() == reflect.Func && v.Type().ConvertibleTo(t) {
+			return hookFunc(v.Convert(t).Interface())
+		}
+	}
+	return nil, fmt.Errorf("hook function must be one of func(), func() error, "+
+		"func(context.Context), or func(context.Context) error, got %T", fn)
+}
+
+// State is the lifecycle state of an App. See App.State.
+type State int
+
+// An App begins in the StateStopped state. Start moves it to StateStarting
+// while OnStart hooks run, and to StateStarted once they all succeed. Stop
+// moves it to StateStopping while OnStop hooks run, and back to StateStopped
+// once they're done.
+const (
+	StateStopped  State = State(lifecycle.Stopped)
+	StateStarting State = State(lifecycle.Starting)
+	StateStarted  State = State(lifecycle.Started)
+	StateStopping State = State(lifecycle.Stopping)
+)
+
+func (s State) String() string {
+	return lifecycle.State(s).String()
+}
+
+// StateError is returned by App.Start and App.Stop when the application is
+// in a state that doesn't allow the operation, such as starting an
+// application that has already started. No hooks run in that case. Use
+// errors.As to tell it apart from errors returned by hooks.
+type StateError struct {
+	// Op is the operation that failed: "start" or "stop".
+	Op string
+
+	// State is the state the application was in.
+	State State
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("cannot %v lifecycle: lifecycle is %v", e.Op, e.State)
+}
+
+// isStateError reports whether err is or wraps a StateError.
+func isStateError(err error) bool {
+	var serr *StateError
+	return errors.As(err, &serr)
+}
+
+// asStateError converts a lifecycle.TransitionError into a StateError, and
+// returns other errors unchanged.
+func asStateError(err error) error {
+	var terr *lifecycle.TransitionError
+	if errors.As(err, &terr) {
+		return &StateError{Op: terr.Op, State: State(terr.State)}
+	}
+	return err
+}
+
+type lifecycleWrapper struct {
+	*lifecycle.Lifecycle
+
+	graph *hookGraph // nil unless hooks run in parallel
+}
 
 func (l *lifecycleWrapper) Append(h Hook) {
-	l.Lifecycle.Append(lifecycle.Hook{
-		OnStart: h.OnStart,
-		OnStop:  h.OnStop,
-	})
+	l.Lifecycle.Append(l.hook(h, ""))
+}
+
+// hook adapts h into a lifecycle.Hook appended by the given module.
+func (l *lifecycleWrapper) hook(h Hook, module string) lifecycle.Hook {
+	return lifecycle.Hook{
+		OnStart:        h.OnStart,
+		OnStop:         h.OnStop,
+		OnStartTimeout: h.OnStartTimeout,
+		OnStopTimeout:  h.OnStopTimeout,
+		Owner:          l.graph.currentOwner(),
+		Module:         module,
+		CallerFrame:    h.callerFrame,
+	}
 }
 
 func (l *lifecycleWrapper) startHookRecords() lifecycle.HookRecords {
@@ -62,3 +251,16 @@ func (l *lifecycleWrapper) stopHookRecords() lifecycle.HookRecords {
 func (l *lifecycleWrapper) runningHookCaller() string {
 	return l.RunningHookCaller()
 }
+
+// moduleLifecycle is the Lifecycle provided inside an fx.Module. It appends
+// hooks to the application's lifecycle, tagged with the module's name.
+type moduleLifecycle struct {
+	lifecycle *lifecycleWrapper
+	name      string
+}
+
+func (l *moduleLifecycle) Append(h Hook) {
+	// Append directly to the internal lifecycle so that it records the
+	// caller of this method, just like lifecycleWrapper.Append.
+	l.lifecycle.Lifecycle.Append(l.lifecycle.hook(h, l.name))
+}
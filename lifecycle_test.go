@@ -0,0 +1,147 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type namedHookFunc func() error
+
+func TestHookConstructors(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	record := func(name string) func() {
+		return func() { calls = append(calls, name) }
+	}
+
+	tests := []struct {
+		desc string
+		give interface{}
+	}{
+		{
+			desc: "func()",
+			give: func() { calls = append(calls, "func()") },
+		},
+		{
+			desc: "func() error",
+			give: func() error {
+				calls = append(calls, "func() error")
+				return nil
+			},
+		},
+		{
+			desc: "func(context.Context)",
+			give: func(context.Context) { calls = append(calls, "func(context.Context)") },
+		},
+		{
+			desc: "func(context.Context) error",
+			give: func(context.Context) error {
+				calls = append(calls, "func(context.Context) error")
+				return nil
+			},
+		},
+		{
+			desc: "named function type",
+			give: namedHookFunc(func() error {
+				calls = append(calls, "named function type")
+				return nil
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			calls = nil
+
+			hook := fx.StartStopHook(tt.give, record("stop"))
+			require.NoError(t, hook.OnStart(context.Background()))
+			require.NoError(t, hook.OnStop(context.Background()))
+			assert.Equal(t, []string{tt.desc, "stop"}, calls)
+
+			calls = nil
+			require.NoError(t, fx.StartHook(tt.give).OnStart(context.Background()))
+			require.NoError(t, fx.StopHook(tt.give).OnStop(context.Background()))
+			assert.Equal(t, []string{tt.desc, tt.desc}, calls)
+		})
+	}
+}
+
+func TestHookConstructorsErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error is returned", func(t *testing.T) {
+		t.Parallel()
+
+		hook := fx.StartHook(func() error { return errors.New("great sadness") })
+		assert.EqualError(t, hook.OnStart(context.Background()), "great sadness")
+		assert.Nil(t, hook.OnStop)
+	})
+
+	t.Run("nil function", func(t *testing.T) {
+		t.Parallel()
+
+		var stop func()
+		hook := fx.StartStopHook(nil, stop)
+		assert.Nil(t, hook.OnStart)
+		assert.Nil(t, hook.OnStop)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		t.Parallel()
+
+		assert.PanicsWithValue(t,
+			"fx.StopHook: hook function must be one of func(), func() error, "+
+				"func(context.Context), or func(context.Context) error, got func(int)",
+			func() { fx.StopHook(func(int) {}) })
+		assert.Panics(t, func() { fx.StartHook(42) })
+	})
+}
+
+func TestHookConstructorsWithLifecycle(t *testing.T) {
+	t.Parallel()
+
+	var started, stopped bool
+	app := fxtest.New(t,
+		fx.Invoke(func(lc fx.Lifecycle) {
+			lc.Append(fx.StartStopHook(
+				func() { started = true },
+				func(context.Context) error {
+					stopped = true
+					return nil
+				},
+			))
+		}),
+	)
+	app.RequireStart()
+	assert.True(t, started)
+	app.RequireStop()
+	assert.True(t, stopped)
+}
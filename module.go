@@ -26,6 +26,7 @@ import (
 	"go.uber.org/dig"
 	"go.uber.org/fx/fxevent"
 	"go.uber.org/fx/internal/fxreflect"
+	"go.uber.org/multierr"
 )
 
 // A container represents a set of constructors to provide
@@ -85,6 +86,19 @@ type module struct {
 	decorators []decorator
 	modules    []*module
 	app        *App
+	errorHooks []ErrorHandler
+
+	// Conditional options passed to fx.If and fx.Unless. If their
+	// predicates pass, their options are applied to child modules that
+	// share this module's Scope.
+	conditionals []conditionalOption
+	sharesScope  bool
+
+	// Used to log events about this module, if fx.WithLogger was passed
+	// to it. Events are buffered in logBuffer until the logger is built.
+	log            fxevent.Logger
+	logBuffer      *logBuffer
+	logConstructor *provide // set only if fx.WithLogger was used
 }
 
 // builds the Scopes using the App's Container. Note that this happens
@@ -93,20 +107,33 @@ type module struct {
 // before the Container can get initialized.
 func (m *module) build(app *App, root *dig.Container) {
 	if m.parent == nil {
+		// The root module gets a Scope of its own like every other module.
+		// Every other module's Scope descends from it, so decorations made
+		// in the root module apply to the whole application.
 		m.scope = root.Scope(m.name)
-		// TODO: Once fx.Decorate is in-place,
-		// use the root container instead of subscope.
+	} else if m.sharesScope {
+		m.scope = m.parent.scope
 	} else {
 		parentScope := m.parent.scope
 		m.scope = parentScope.Scope(m.name)
 	}
 
+	if m.logConstructor != nil {
+		// Hold on to this module's events until its logger is built.
+		m.logBuffer = new(logBuffer)
+		m.log = m.logBuffer
+	}
+
 	for _, mod := range m.modules {
 		mod.build(app, root)
 	}
 }
 
 func (m *module) provideAll() {
+	if m.parent != nil && !m.sharesScope {
+		m.provideLifecycle()
+	}
+
 	for _, p := range m.provides {
 		m.provide(p)
 	}
@@ -121,9 +148,35 @@ func (m *module) provide(p provide) {
 		return
 	}
 
-	var info dig.ProvideInfo
-	if err := runProvide(m.scope, p, dig.FillProvideInfo(&info), dig.Export(true)); err != nil {
+	var (
+		info dig.ProvideInfo
+		c    container = m.scope
+	)
+	hc := m.hookGraphContainer()
+	if hc != nil {
+		c = hc
+	}
+
+	// Constructors wrapped with fx.Lazy are replaced with one that
+	// provides a function to run them.
+	var (
+		target = p
+		err    error
+	)
+	if l, ok := p.Target.(lazyConstructor); ok {
+		target.Target, err = m.lazyProvider(l)
+		if err != nil {
+			err = fmt.Errorf("fx.Provide(%v) from:\n%+vFailed: %v", l, p.Stack, err)
+		}
+	}
+	if err == nil {
+		err = runProvide(c, target, dig.FillProvideInfo(&info), dig.Export(!p.Private))
+	}
+
+	if err != nil {
 		m.app.err = err
+	} else if hc != nil && hc.node != nil {
+		hc.graph.add(hc.node, info.Inputs, info.Outputs)
 	}
 	var ev fxevent.Event
 	switch {
@@ -147,32 +200,127 @@ func (m *module) provide(p provide) {
 			Err:             m.app.err,
 		}
 	}
-	m.app.log.LogEvent(ev)
+	m.logger().LogEvent(ev)
+}
+
+// provideLifecycle makes a Lifecycle that tags hooks with this module's name
+// available to the module and its children.
+func (m *module) provideLifecycle() {
+	if m.app.err != nil {
+		return
+	}
+
+	lc := &moduleLifecycle{lifecycle: m.app.lifecycle, name: m.name}
+	err := m.scope.Provide(func() Lifecycle { return lc }, dig.Export(false))
+	if err != nil {
+		m.app.err = err
+	}
+}
+
+// hookGraphContainer returns a container that records constructors and
+// decorators in the app's hookGraph, or nil if hooks don't run in parallel.
+func (m *module) hookGraphContainer() *hookGraphContainer {
+	if m.app.hookGraph == nil {
+		return nil
+	}
+	return &hookGraphContainer{container: m.scope, graph: m.app.hookGraph}
+}
+
+// logger returns the logger for events about this module: the logger passed
+// to fx.WithLogger in this module or the closest of its parents, or the
+// application's logger.
+func (m *module) logger() fxevent.Logger {
+	for mod := m; mod != nil; mod = mod.parent {
+		if mod.log != nil {
+			return mod.log
+		}
+	}
+	return m.app.log
+}
+
+// constructCustomLoggers builds the loggers passed to fx.WithLogger in this
+// module and its children, and flushes their buffered events to them. If a
+// logger fails to build, its events are flushed to the parent's logger
+// instead.
+func (m *module) constructCustomLoggers() error {
+	var errs []error
+	if m.logConstructor != nil {
+		if err := m.constructCustomLogger(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, mod := range m.modules {
+		if err := mod.constructCustomLoggers(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return multierr.Combine(errs...)
 }
 
-func (m *module) executeInvokes() error {
+func (m *module) constructCustomLogger() (err error) {
+	p := m.logConstructor
+	fname := fxreflect.FuncName(p.Target)
+	defer func() {
+		if err != nil {
+			m.log = nil
+			m.logBuffer.Connect(m.logger())
+		}
+		m.logger().LogEvent(&fxevent.LoggerInitialized{
+			Err:             err,
+			ConstructorName: fname,
+		})
+	}()
+
+	// Keep the logger private to this module so that it doesn't conflict
+	// with the application's or other modules' loggers.
+	if err := m.scope.Provide(p.Target, dig.Export(false)); err != nil {
+		return fmt.Errorf("fx.WithLogger(%v) from:\n%+vFailed: %v",
+			fname, p.Stack, err)
+	}
+
+	return m.scope.Invoke(func(log fxevent.Logger) {
+		m.log = log
+		m.logBuffer.Connect(log)
+	})
+}
+
+// errorHandlers returns the handlers passed to fx.ErrorHook in this module
+// and its parents, innermost first.
+func (m *module) errorHandlers() errorHandlerList {
+	var hs errorHandlerList
+	for mod := m; mod != nil; mod = mod.parent {
+		hs = append(hs, mod.errorHooks...)
+	}
+	return hs
+}
+
+// executeInvokes runs the invokes of this module and its children. If an
+// invoke fails, it returns the module that the invoke belongs to with the
+// error.
+func (m *module) executeInvokes() (failed *module, err error) {
 	for _, invoke := range m.invokes {
 		if err := m.executeInvoke(invoke); err != nil {
-			return err
+			return m, err
 		}
 	}
 
 	for _, m := range m.modules {
-		if err := m.executeInvokes(); err != nil {
-			return err
+		if failed, err := m.executeInvokes(); err != nil {
+			return failed, err
 		}
 	}
-	return nil
+	return nil, nil
 }
 
 func (m *module) executeInvoke(i invoke) (err error) {
 	fnName := fxreflect.FuncName(i.Target)
-	m.app.log.LogEvent(&fxevent.Invoking{
+	m.logger().LogEvent(&fxevent.Invoking{
 		FunctionName: fnName,
 		ModuleName:   m.name,
 	})
 	err = runInvoke(m.scope, i)
-	m.app.log.LogEvent(&fxevent.Invoked{
+	m.logger().LogEvent(&fxevent.Invoked{
 		FunctionName: fnName,
 		ModuleName:   m.name,
 		Err:          err,
@@ -183,22 +331,39 @@ func (m *module) executeInvoke(i invoke) (err error) {
 
 func (m *module) decorate() (err error) {
 	for _, decorator := range m.decorators {
-		var info dig.DecorateInfo
-		err := runDecorator(m.scope, decorator, dig.FillDecorateInfo(&info))
+		// Decorators marked with fx.AtRoot are applied to the root module's
+		// Scope so that they reach the whole application.
+		target := m
+		if decorator.AtRoot {
+			target = m.app.root
+		}
+
+		var (
+			info dig.DecorateInfo
+			c    container = target.scope
+		)
+		hc := target.hookGraphContainer()
+		if hc != nil {
+			c = hc
+		}
+		err := runDecorator(c, decorator, dig.FillDecorateInfo(&info))
+		if err == nil && hc != nil && hc.node != nil {
+			hc.graph.add(hc.node, info.Inputs, info.Outputs)
+		}
 		outputNames := make([]string, len(info.Outputs))
 		for i, o := range info.Outputs {
 			outputNames[i] = o.String()
 		}
 
 		if decorator.IsReplace {
-			m.app.log.LogEvent(&fxevent.Replaced{
+			m.logger().LogEvent(&fxevent.Replaced{
 				ModuleName:      m.name,
 				OutputTypeNames: outputNames,
 				Err:             err,
 			})
 		} else {
 
-			m.app.log.LogEvent(&fxevent.Decorated{
+			m.logger().LogEvent(&fxevent.Decorated{
 				DecoratorName:   fxreflect.FuncName(decorator.Target),
 				ModuleName:      m.name,
 				OutputTypeNames: outputNames,
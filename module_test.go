@@ -21,6 +21,7 @@
 package fx_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -211,6 +212,49 @@ func TestModuleSuccess(t *testing.T) {
 
 		defer app.RequireStart().RequireStop()
 	})
+
+	t.Run("private provide is visible to module and children", func(t *testing.T) {
+		t.Parallel()
+
+		var called int
+		app := fxtest.New(t,
+			fx.Module("parent",
+				fx.Provide(func() *Logger {
+					return &Logger{Name: "private"}
+				}, fx.Private),
+				fx.Invoke(func(l *Logger) {
+					assert.Equal(t, "private", l.Name)
+					called++
+				}),
+				fx.Module("child",
+					fx.Invoke(func(l *Logger) {
+						assert.Equal(t, "private", l.Name)
+						called++
+					}),
+				),
+			),
+		)
+		defer app.RequireStart().RequireStop()
+		assert.Equal(t, 2, called)
+	})
+
+	t.Run("private provides of the same type in sibling modules", func(t *testing.T) {
+		t.Parallel()
+
+		newModule := func(name string) fx.Option {
+			return fx.Module(name,
+				fx.Provide(func() *Logger {
+					return &Logger{Name: name}
+				}, fx.Private),
+				fx.Invoke(func(l *Logger) {
+					assert.Equal(t, name, l.Name)
+				}),
+			)
+		}
+
+		app := fxtest.New(t, newModule("a"), newModule("b"))
+		defer app.RequireStart().RequireStop()
+	})
 }
 
 func TestModuleFailures(t *testing.T) {
@@ -257,6 +301,25 @@ func TestModuleFailures(t *testing.T) {
 		assert.Contains(t, err.Error(), "already provided by ")
 	})
 
+	t.Run("private provide is not visible to parent", func(t *testing.T) {
+		t.Parallel()
+
+		type A struct{}
+
+		app := NewForTest(t,
+			fx.Module("mod",
+				fx.Provide(func() A { return A{} }, fx.Private),
+			),
+			fx.Invoke(func(A) {
+				require.Fail(t, "this should not be called")
+			}),
+		)
+
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: fx_test.A")
+	})
+
 	t.Run("providing Modules should fail", func(t *testing.T) {
 		t.Parallel()
 		app := NewForTest(t,
@@ -370,10 +433,6 @@ func TestModuleFailures(t *testing.T) {
 				desc: "StopTimeout Option",
 				opt:  fx.StopTimeout(time.Second),
 			},
-			{
-				desc: "WithLogger Option",
-				opt:  fx.WithLogger(func() fxevent.Logger { return new(fxlog.Spy) }),
-			},
 		}
 
 		for _, tt := range tests {
@@ -391,3 +450,160 @@ func TestModuleFailures(t *testing.T) {
 		}
 	})
 }
+
+func TestModuleLifecycle(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+	type B struct{}
+
+	noop := func(context.Context) error { return nil }
+	spy := new(fxlog.Spy)
+	app := fxtest.New(t,
+		fx.WithLogger(func() fxevent.Logger { return spy }),
+		fx.Module("a",
+			fx.Provide(func(lc fx.Lifecycle) A {
+				lc.Append(fx.Hook{OnStart: noop, OnStop: noop})
+				return A{}
+			}),
+			fx.Module("b",
+				fx.Invoke(func(lc fx.Lifecycle, _ A) {
+					lc.Append(fx.Hook{OnStart: noop})
+				}),
+			),
+		),
+		fx.Invoke(func(lc fx.Lifecycle) {
+			lc.Append(fx.Hook{OnStart: noop})
+		}),
+	)
+	app.RequireStart().RequireStop()
+
+	var modules []string
+	for _, e := range spy.Events().SelectByTypeName("OnStartExecuting") {
+		modules = append(modules, e.(*fxevent.OnStartExecuting).ModuleName)
+	}
+	assert.Equal(t, []string{"", "a", "b"}, modules,
+		"hooks must run in order and be tagged with their module")
+
+	stopped := spy.Events().SelectByTypeName("OnStopExecuted")
+	require.Equal(t, 1, stopped.Len())
+	assert.Equal(t, "a", stopped[0].(*fxevent.OnStopExecuted).ModuleName)
+
+	reports := spy.Events().SelectByTypeName("HookRuntimes")
+	require.Equal(t, 2, reports.Len())
+
+	var started []string
+	for _, m := range reports[0].(*fxevent.HookRuntimes).Modules {
+		started = append(started, m.ModuleName)
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, started)
+
+	stopReport := reports[1].(*fxevent.HookRuntimes)
+	require.Len(t, stopReport.Modules, 1)
+	assert.Equal(t, "a", stopReport.Modules[0].ModuleName)
+}
+
+func TestModuleLogger(t *testing.T) {
+	t.Parallel()
+
+	type Config struct{ Name string }
+
+	invokedIn := func(spy *fxlog.Spy) []string {
+		var modules []string
+		for _, e := range spy.Events().SelectByTypeName("Invoking") {
+			modules = append(modules, e.(*fxevent.Invoking).ModuleName)
+		}
+		return modules
+	}
+
+	t.Run("events go to the module's logger", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			appSpy    = new(fxlog.Spy)
+			moduleSpy = new(fxlog.Spy)
+		)
+		app := fxtest.New(t,
+			fx.WithLogger(func() fxevent.Logger { return appSpy }),
+			fx.Module("lib",
+				fx.Supply(&Config{Name: "lib"}),
+				fx.WithLogger(func(cfg *Config) fxevent.Logger {
+					assert.Equal(t, "lib", cfg.Name)
+					return moduleSpy
+				}),
+				fx.Invoke(func() {}),
+				fx.Module("child",
+					fx.Invoke(func() {}),
+				),
+			),
+			fx.Module("sibling",
+				fx.Invoke(func() {}),
+			),
+			fx.Invoke(func() {}),
+		)
+		app.RequireStart().RequireStop()
+
+		assert.Equal(t, []string{"lib", "child"}, invokedIn(moduleSpy))
+		assert.Equal(t, []string{"", "sibling"}, invokedIn(appSpy))
+
+		require.Equal(t, 1, moduleSpy.Events().SelectByTypeName("Supplied").Len())
+		assert.Zero(t, appSpy.Events().SelectByTypeName("Supplied").Len(),
+			"events buffered before the module's logger was built must go to it")
+		assert.NotZero(t, appSpy.Events().SelectByTypeName("Started").Len(),
+			"application events must go to the application's logger")
+		assert.Zero(t, moduleSpy.Events().SelectByTypeName("Started").Len())
+	})
+
+	t.Run("logger failure falls back to the parent's logger", func(t *testing.T) {
+		t.Parallel()
+
+		appSpy := new(fxlog.Spy)
+		app := NewForTest(t,
+			fx.WithLogger(func() fxevent.Logger { return appSpy }),
+			fx.Module("lib",
+				fx.WithLogger(func() (fxevent.Logger, error) {
+					return nil, errors.New("great sadness")
+				}),
+				fx.Invoke(func() {}),
+			),
+		)
+
+		err := app.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+
+		initialized := appSpy.Events().SelectByTypeName("LoggerInitialized")
+		require.Equal(t, 2, initialized.Len())
+		assert.NoError(t, initialized[0].(*fxevent.LoggerInitialized).Err)
+		assert.Error(t, initialized[1].(*fxevent.LoggerInitialized).Err)
+	})
+}
+
+func TestModuleErrorHook(t *testing.T) {
+	t.Parallel()
+
+	type A struct{}
+
+	var calls []string
+	handler := func(name string) fx.ErrorHandler {
+		return errHandlerFunc(func(error) {
+			calls = append(calls, name)
+		})
+	}
+
+	app := NewForTest(t,
+		fx.ErrorHook(handler("app")),
+		fx.Module("parent",
+			fx.ErrorHook(handler("parent")),
+			fx.Module("child",
+				fx.ErrorHook(handler("child")),
+				fx.Invoke(func(A) {}),
+			),
+		),
+		fx.Module("sibling",
+			fx.ErrorHook(handler("sibling")),
+		),
+	)
+	require.Error(t, app.Err())
+	assert.Equal(t, []string{"child", "parent", "app"}, calls)
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.uber.org/dig"
+	"go.uber.org/fx/internal/lifecycle"
+)
+
+// ParallelHooks runs lifecycle hooks concurrently when they were appended by
+// constructors that don't depend on each other.
+//
+// By default, OnStart hooks run one at a time in the order they were
+// appended, and OnStop hooks run one at a time in reverse order. With
+// ParallelHooks, Fx uses the dependency graph to relax this: an OnStart hook
+// appended by a constructor still runs only after the OnStart hooks of all
+// the constructors it (transitively) depends on have succeeded, and its
+// OnStop hook runs before theirs. Hooks of unrelated constructors run at the
+// same time.
+//
+// Hooks that weren't appended by a constructor or decorator, such as hooks
+// appended from an fx.Invoke, keep their strict order with respect to all
+// other hooks.
+//
+// If an OnStart hook fails, no new hooks are started, but hooks that are
+// already running are allowed to finish. Stop then runs the OnStop hooks of
+// every hook whose OnStart succeeded.
+//
+// This option may only be passed to the top-level App.
+func ParallelHooks() Option {
+	return parallelHooksOption{}
+}
+
+type parallelHooksOption struct{}
+
+func (parallelHooksOption) apply(m *module) {
+	if m.parent != nil {
+		m.app.err = fmt.Errorf("fx.ParallelHooks Option should be passed to top-level App, " +
+			"not to fx.Module")
+	} else {
+		m.app.parallelHooks = true
+	}
+}
+
+func (parallelHooksOption) String() string {
+	return "fx.ParallelHooks()"
+}
+
+// hookGraph tracks the dependencies between constructors and decorators so
+// that the lifecycle hooks they append can be run in parallel.
+//
+// Dependencies are derived from the string forms of dig's inputs and outputs,
+// so unrelated types that share a name appear to depend on each other. This
+// only reduces parallelism; it never drops a real dependency.
+type hookGraph struct {
+	mu        sync.Mutex
+	producers map[string][]*hookNode // constructors and decorators of each key
+	calling   []*hookNode            // stack of functions being called
+}
+
+// hookNode is a single constructor or decorator in a hookGraph.
+type hookNode struct {
+	inputs []string // keys of the node's dependencies
+
+	owner    *lifecycle.Owner // nil until first needed
+	visiting bool             // used to break cycles through decorators
+}
+
+func newHookGraph() *hookGraph {
+	return &hookGraph{producers: make(map[string][]*hookNode)}
+}
+
+// hookGraphKey converts the string form of a dig.Input or a dig.Output into
+// a key shared by the consumers and the producers of that value.
+func hookGraphKey(s string) string {
+	// Value groups are matched by name only because inputs are slices
+	// while outputs are individual values.
+	if i := strings.Index(s, `group = "`); i >= 0 {
+		return strings.TrimSuffix(s[i:], "]")
+	}
+	s = strings.Replace(s, "[optional]", "", 1)
+	return strings.Replace(s, "[optional, ", "[", 1)
+}
+
+// add registers a node with the graph once dig has accepted it.
+func (g *hookGraph) add(n *hookNode, inputs []*dig.Input, outputs []*dig.Output) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, in := range inputs {
+		n.inputs = append(n.inputs, hookGraphKey(in.String()))
+	}
+	for _, out := range outputs {
+		k := hookGraphKey(out.String())
+		g.producers[k] = append(g.producers[k], n)
+	}
+}
+
+// ownerOf returns the lifecycle.Owner for the given node, building it and the
+// Owners of its dependencies if needed. The caller must hold g.mu.
+func (g *hookGraph) ownerOf(n *hookNode) *lifecycle.Owner {
+	if n.owner != nil || n.visiting {
+		return n.owner
+	}
+
+	n.visiting = true
+	var deps []*lifecycle.Owner
+	for _, k := range n.inputs {
+		for _, p := range g.producers[k] {
+			if p != n {
+				deps = append(deps, g.ownerOf(p))
+			}
+		}
+	}
+	n.visiting = false
+
+	n.owner = lifecycle.NewOwner(deps...)
+	return n.owner
+}
+
+// currentOwner returns the Owner of the constructor or decorator that is
+// currently being called, or nil if there isn't one.
+func (g *hookGraph) currentOwner() *lifecycle.Owner {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.calling) == 0 {
+		return nil
+	}
+	return g.ownerOf(g.calling[len(g.calling)-1])
+}
+
+// wrap returns a function with the same signature as fn that records n as
+// the function being called for the duration of the call.
+func (g *hookGraph) wrap(fn interface{}, n *hookNode) interface{} {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		// Let dig report the error.
+		return fn
+	}
+
+	return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		g.mu.Lock()
+		g.calling = append(g.calling, n)
+		g.mu.Unlock()
+
+		defer func() {
+			g.mu.Lock()
+			g.calling = g.calling[:len(g.calling)-1]
+			g.mu.Unlock()
+		}()
+
+		if ft.IsVariadic() {
+			return fv.CallSlice(args)
+		}
+		return fv.Call(args)
+	}).Interface()
+}
+
+// hookGraphContainer is a container that wraps the constructor or decorator
+// passed to it so that lifecycle hooks appended by it can be attributed to
+// it.
+type hookGraphContainer struct {
+	container
+
+	graph *hookGraph
+	node  *hookNode // node for the wrapped function
+}
+
+func (c *hookGraphContainer) Provide(ctor interface{}, opts ...dig.ProvideOption) error {
+	c.node = new(hookNode)
+	if v := reflect.ValueOf(ctor); v.Kind() == reflect.Func {
+		// Report errors against the original constructor. Options
+		// passed in take precedence.
+		opts = append([]dig.ProvideOption{dig.LocationForPC(v.Pointer())}, opts...)
+	}
+	return c.container.Provide(c.graph.wrap(ctor, c.node), opts...)
+}
+
+func (c *hookGraphContainer) Decorate(dcor interface{}, opts ...dig.DecorateOption) error {
+	c.node = new(hookNode)
+	return c.container.Decorate(c.graph.wrap(dcor, c.node), opts...)
+}
@@ -21,6 +21,7 @@
 package fx
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 )
@@ -34,15 +35,71 @@ import (
 // constructor wiring to build a few structs, but then extract those structs
 // for further testing.
 func Populate(targets ...interface{}) Option {
+	fn, err := populateFunc("Populate", targets)
+	if err != nil {
+		return Error(err)
+	}
+	return Invoke(fn)
+}
+
+// Resolve sets targets with values from the dependency injection container
+// of an application returned by New. It accepts the same targets as Populate,
+// but the values to look up don't need to be known before the application is
+// built.
+//
+//	app := fx.New(fx.Provide(newLogger))
+//	var logger *zap.Logger
+//	if err := app.Resolve(&logger); err != nil {
+//		// ...
+//	}
+//
+// Resolve returns the application's initialization error, if any, or the
+// error reported by the container if a value can't be built. Values provided
+// privately inside an fx.Module are not visible to Resolve.
+//
+// Lifecycle hooks appended by constructors that Resolve runs for the first
+// time behave like those of constructors wrapped with Lazy: if the
+// application has already started, their OnStart hooks run before Resolve
+// returns, limited by the StartTimeout, and their OnStop hooks run when the
+// application stops. If an OnStart hook fails, Resolve returns its error.
+//
+// Constructors run by Resolve may call functions provided with Lazy, but only
+// on the goroutine running them: a constructor that waits for another
+// goroutine to call such a function never returns.
+func (app *App) Resolve(targets ...interface{}) error {
+	if err := app.Err(); err != nil {
+		return err
+	}
+
+	fn, err := populateFunc("Resolve", targets)
+	if err != nil {
+		return err
+	}
+
+	if err := app.withContainer(func() error {
+		return app.root.scope.Invoke(fn)
+	}); err != nil {
+		return err
+	}
+
+	// Constructors that ran for the first time may have appended hooks.
+	ctx, cancel := app.clock.WithTimeout(context.Background(), app.StartTimeout())
+	defer cancel()
+	return app.lifecycle.StartAppended(ctx)
+}
+
+// populateFunc builds a function that sets targets to its arguments.
+// method names the caller in errors.
+func populateFunc(method string, targets []interface{}) (interface{}, error) {
 	// Validate all targets are non-nil pointers.
 	targetTypes := make([]reflect.Type, len(targets))
 	for i, t := range targets {
 		if t == nil {
-			return Error(fmt.Errorf("failed to Populate: target %v is nil", i+1))
+			return nil, fmt.Errorf("failed to %v: target %v is nil", method, i+1)
 		}
 		rt := reflect.TypeOf(t)
 		if rt.Kind() != reflect.Ptr {
-			return Error(fmt.Errorf("failed to Populate: target %v is not a pointer type, got %T", i+1, t))
+			return nil, fmt.Errorf("failed to %v: target %v is not a pointer type, got %T", method, i+1, t)
 		}
 
 		targetTypes[i] = reflect.TypeOf(t).Elem()
@@ -63,5 +120,5 @@ func Populate(targets ...interface{}) Option {
 		}
 		return nil
 	})
-	return Invoke(fn.Interface())
+	return fn.Interface(), nil
 }
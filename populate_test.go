@@ -21,6 +21,7 @@
 package fx_test
 
 import (
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -337,3 +338,138 @@ func TestPopulateValidateApp(t *testing.T) {
 		})
 	}
 }
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	type t1 struct{ name string }
+	type t2 struct{}
+
+	t.Run("resolve values", func(t *testing.T) {
+		t.Parallel()
+
+		app := fxtest.New(t,
+			Provide(func() *t1 { return &t1{name: "foo"} }),
+			Provide(func() *t2 { return &t2{} }),
+		)
+
+		var (
+			v1 *t1
+			v2 *t2
+		)
+		require.NoError(t, app.Resolve(&v1, &v2))
+		assert.Equal(t, "foo", v1.name)
+		assert.NotNil(t, v2)
+
+		var again *t1
+		require.NoError(t, app.Resolve(&again))
+		assert.Same(t, v1, again, "values must be shared with the app")
+	})
+
+	t.Run("hooks appended after start", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		app := fxtest.New(t,
+			Provide(func(lc Lifecycle) *t1 {
+				lc.Append(StartStopHook(
+					func() { calls = append(calls, "start") },
+					func() { calls = append(calls, "stop") },
+				))
+				return &t1{}
+			}),
+		)
+		app.RequireStart()
+
+		var v *t1
+		require.NoError(t, app.Resolve(&v))
+		assert.Equal(t, []string{"start"}, calls, "OnStart must run before Resolve returns")
+
+		app.RequireStop()
+		assert.Equal(t, []string{"start", "stop"}, calls)
+	})
+
+	t.Run("OnStart hook fails", func(t *testing.T) {
+		t.Parallel()
+
+		app := fxtest.New(t,
+			Provide(func(lc Lifecycle) *t1 {
+				lc.Append(StartHook(func() error {
+					return errors.New("great sadness")
+				}))
+				return &t1{}
+			}),
+		)
+		defer app.RequireStart().RequireStop()
+
+		var v *t1
+		assert.EqualError(t, app.Resolve(&v), "great sadness")
+	})
+
+	t.Run("resolve fx.In struct", func(t *testing.T) {
+		t.Parallel()
+
+		app := fxtest.New(t,
+			Provide(Annotated{
+				Name:   "foo",
+				Target: func() *t1 { return &t1{name: "foo"} },
+			}),
+		)
+
+		var params struct {
+			In
+
+			T1 *t1 `name:"foo"`
+		}
+		require.NoError(t, app.Resolve(&params))
+		assert.Equal(t, "foo", params.T1.name)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		app := fxtest.New(t)
+
+		var v *t1
+		tests := []struct {
+			msg     string
+			targets []interface{}
+			wantErr string
+		}{
+			{
+				msg:     "missing type",
+				targets: []interface{}{&v},
+				wantErr: "missing type: *fx_test.t1",
+			},
+			{
+				msg:     "not a pointer",
+				targets: []interface{}{t1{}},
+				wantErr: "failed to Resolve: target 1 is not a pointer type",
+			},
+			{
+				msg:     "nil argument",
+				targets: []interface{}{&v, nil},
+				wantErr: "failed to Resolve: target 2 is nil",
+			},
+		}
+
+		for _, tt := range tests {
+			err := app.Resolve(tt.targets...)
+			require.Error(t, err, tt.msg)
+			assert.Contains(t, err.Error(), tt.wantErr, tt.msg)
+		}
+	})
+
+	t.Run("app error", func(t *testing.T) {
+		t.Parallel()
+
+		app := NewForTest(t,
+			NopLogger,
+			Invoke(func(*t2) {}),
+		)
+		require.Error(t, app.Err())
+
+		var v *t1
+		assert.Equal(t, app.Err(), app.Resolve(&v))
+	})
+}
@@ -59,6 +59,9 @@ import (
 // possible, and should avoid spawning goroutines. Things like server listen
 // loops, background timer loops, and background processing goroutines should
 // instead be managed using Lifecycle callbacks.
+//
+// Passing Private alongside the constructors hides their outputs from the
+// rest of the application; see Private for details.
 func Provide(constructors ...interface{}) Option {
 	return provideOption{
 		Targets: constructors,
@@ -72,10 +75,21 @@ type provideOption struct {
 }
 
 func (o provideOption) apply(mod *module) {
+	var private bool
+	targets := make([]interface{}, 0, len(o.Targets))
 	for _, target := range o.Targets {
+		if _, ok := target.(privateOption); ok {
+			private = true
+			continue
+		}
+		targets = append(targets, target)
+	}
+
+	for _, target := range targets {
 		mod.provides = append(mod.provides, provide{
-			Target: target,
-			Stack:  o.Stack,
+			Target:  target,
+			Stack:   o.Stack,
+			Private: private,
 		})
 	}
 }
@@ -83,11 +97,31 @@ func (o provideOption) apply(mod *module) {
 func (o provideOption) String() string {
 	items := make([]string, len(o.Targets))
 	for i, c := range o.Targets {
+		if _, ok := c.(privateOption); ok {
+			items[i] = "fx.Private"
+			continue
+		}
 		items[i] = fxreflect.FuncName(c)
 	}
 	return fmt.Sprintf("fx.Provide(%s)", strings.Join(items, ", "))
 }
 
+// Private may be passed to Provide alongside constructors to make their
+// outputs visible only inside the fx.Module they are provided to, and inside
+// that module's child modules. Other parts of the application can't depend on
+// them, and may provide the same types themselves without conflict.
+//
+//	fx.Module("client",
+//	  fx.Provide(newHTTPClient, fx.Private),
+//	  fx.Provide(newAPIClient), // depends on *http.Client
+//	)
+//
+// Private has no effect on constructors provided to the top-level App, since
+// all modules are children of the application.
+var Private = privateOption{}
+
+type privateOption struct{}
+
 func runProvide(c container, p provide, opts ...dig.ProvideOption) error {
 	constructor := p.Target
 	if _, ok := constructor.(Option); ok {
@@ -96,17 +130,20 @@ func runProvide(c container, p provide, opts ...dig.ProvideOption) error {
 			constructor, p.Stack)
 	}
 
+	option := "fx.Provide"
+	if p.IsSupply {
+		option = "fx.Supply"
+	}
+
 	switch constructor := constructor.(type) {
 	case annotationError:
 		// fx.Annotate failed. Turn it into an Fx error.
-		return fmt.Errorf(
-			"encountered error while applying annotation using fx.Annotate to %s: %+v",
-			fxreflect.FuncName(constructor.target), constructor.err)
+		return constructor.report(option, p.Stack)
 
 	case annotated:
-		ctor, err := constructor.Build()
+		ctor, err := constructor.build(option, p.Stack)
 		if err != nil {
-			return fmt.Errorf("fx.Provide(%v) from:\n%+vFailed: %v", constructor, p.Stack, err)
+			return err
 		}
 
 		opts = append(opts, dig.LocationForPC(constructor.FuncPtr))
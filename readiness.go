@@ -0,0 +1,154 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/fx/internal/fxreflect"
+	"go.uber.org/multierr"
+)
+
+// Readiness allows constructors to register checks that must pass before the
+// application is considered ready. It is provided to all Fx applications.
+//
+// An application that has run all of its OnStart hooks has started, but it
+// may not be ready yet: a server that listens on a separate goroutine, for
+// example, may still be binding its port. Once the OnStart hooks succeed,
+// Start runs all readiness checks concurrently and returns only after they
+// have all passed, within the same deadline as the OnStart hooks. If any
+// check fails, Start fails and the application is rolled back.
+//
+// Once all checks pass, the application emits an fxevent.Ready event and
+// closes the channel returned by App.Ready.
+type Readiness interface {
+	Append(ReadinessCheck)
+}
+
+// A ReadinessCheck reports whether a component is ready. It should block
+// until the component is ready and return nil, or return an error if the
+// component fails to become ready or if the context is done first.
+type ReadinessCheck func(context.Context) error
+
+type readiness struct {
+	mu     sync.Mutex
+	checks []ReadinessCheck
+	ready  chan struct{} // closed once all checks have passed; replaced on reset
+}
+
+func newReadiness() *readiness {
+	return &readiness{ready: make(chan struct{})}
+}
+
+var _ Readiness = (*readiness)(nil)
+
+func (r *readiness) Append(check ReadinessCheck) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, check)
+}
+
+// wait runs all readiness checks concurrently, and returns once all of them
+// have passed or one of them has failed. After the first failure, the
+// context passed to the remaining checks is canceled.
+func (r *readiness) wait(ctx context.Context) error {
+	r.mu.Lock()
+	checks := r.checks
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		errs   []error
+		failed bool // a check failed and canceled ctx
+	)
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check ReadinessCheck) {
+			defer wg.Done()
+
+			err := check(ctx)
+			if err == nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			// Don't report checks that gave up only because another
+			// check failed first.
+			if failed && errors.Is(err, context.Canceled) {
+				return
+			}
+			failed = true
+			errs = append(errs, fmt.Errorf("readiness check %v failed: %w",
+				fxreflect.FuncName(check), err))
+			cancel()
+		}(check)
+	}
+	wg.Wait()
+
+	if err := multierr.Combine(errs...); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-r.ready:
+		// Already ready.
+	default:
+		close(r.ready)
+	}
+	return nil
+}
+
+// readyChan returns the channel that is closed once all checks pass.
+func (r *readiness) readyChan() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.ready
+}
+
+// reset replaces the ready channel if it has been closed, so that the checks
+// can pass again after the application restarts.
+func (r *readiness) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	select {
+	case <-r.ready:
+		r.ready = make(chan struct{})
+	default:
+	}
+}
+
+func (app *App) readinessChecks() Readiness {
+	return app.readiness
+}
@@ -74,24 +74,39 @@ import (
 //	fx.Replace(
 //		fx.Annotate(os.Stderr, fx.As(new(io.Writer)))
 //	)
+//
+// Like decorations, replacements made inside an fx.Module are visible only
+// to that module and its children. Pass AtRoot alongside the values to
+// replace them for the whole application instead.
 func Replace(values ...interface{}) Option {
-	decorators := make([]interface{}, len(values)) // one function per value
-	types := make([]reflect.Type, len(values))
-	for i, value := range values {
+	var atRoot bool
+	decorators := make([]interface{}, 0, len(values)) // one function per value
+	types := make([]reflect.Type, 0, len(values))
+	for _, value := range values {
 		switch value := value.(type) {
+		case atRootOption:
+			atRoot = true
+		case annotationError:
+			// Reported when the value is decorated.
+			_, typ := newReplaceDecorator(value.target)
+			decorators = append(decorators, value)
+			types = append(types, typ)
 		case annotated:
 			var typ reflect.Type
 			value.Target, typ = newReplaceDecorator(value.Target)
-			decorators[i] = value
-			types[i] = typ
+			decorators = append(decorators, value)
+			types = append(types, typ)
 		default:
-			decorators[i], types[i] = newReplaceDecorator(value)
+			decorator, typ := newReplaceDecorator(value)
+			decorators = append(decorators, decorator)
+			types = append(types, typ)
 		}
 	}
 
 	return replaceOption{
 		Targets: decorators,
 		Types:   types,
+		AtRoot:  atRoot,
 		Stack:   fxreflect.CallerStack(1, 0),
 	}
 }
@@ -99,6 +114,7 @@ func Replace(values ...interface{}) Option {
 type replaceOption struct {
 	Targets []interface{}
 	Types   []reflect.Type // type of value produced by constructor[i]
+	AtRoot  bool
 	Stack   fxreflect.Stack
 }
 
@@ -108,15 +124,19 @@ func (o replaceOption) apply(m *module) {
 			Target:    target,
 			Stack:     o.Stack,
 			IsReplace: true,
+			AtRoot:    o.AtRoot,
 		})
 	}
 }
 
 func (o replaceOption) String() string {
-	items := make([]string, 0, len(o.Targets))
+	items := make([]string, 0, len(o.Targets)+1)
 	for _, typ := range o.Types {
 		items = append(items, typ.String())
 	}
+	if o.AtRoot {
+		items = append(items, "fx.AtRoot")
+	}
 	return fmt.Sprintf("fx.Replace(%s)", strings.Join(items, ", "))
 }
 
@@ -78,6 +78,59 @@ func TestReplaceSuccess(t *testing.T) {
 		defer app.RequireStart().RequireStop()
 	})
 
+	t.Run("replace in a module does not leak to siblings", func(t *testing.T) {
+		t.Parallel()
+
+		type A struct {
+			Value string
+		}
+
+		app := fxtest.New(t,
+			fx.Provide(func() *A { return &A{Value: "a"} }),
+			fx.Module("child",
+				fx.Replace(&A{Value: "A"}),
+				fx.Invoke(func(a *A) {
+					assert.Equal(t, "A", a.Value)
+				}),
+			),
+			fx.Module("sibling",
+				fx.Invoke(func(a *A) {
+					assert.Equal(t, "a", a.Value)
+				}),
+			),
+			fx.Invoke(func(a *A) {
+				assert.Equal(t, "a", a.Value)
+			}),
+		)
+		defer app.RequireStart().RequireStop()
+	})
+
+	t.Run("replace at root from a module", func(t *testing.T) {
+		t.Parallel()
+
+		type A struct {
+			Value string
+		}
+
+		var values []string
+		app := fxtest.New(t,
+			fx.Provide(func() *A { return &A{Value: "a"} }),
+			fx.Module("child",
+				fx.Replace(&A{Value: "A"}, fx.AtRoot),
+			),
+			fx.Module("sibling",
+				fx.Invoke(func(a *A) {
+					values = append(values, a.Value)
+				}),
+			),
+			fx.Invoke(func(a *A) {
+				values = append(values, a.Value)
+			}),
+		)
+		defer app.RequireStart().RequireStop()
+		assert.Equal(t, []string{"A", "A"}, values)
+	})
+
 	t.Run("replace with annotate", func(t *testing.T) {
 		t.Parallel()
 
@@ -161,6 +214,7 @@ func TestReplaceFailure(t *testing.T) {
 		)
 		err := app.Err()
 		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "fx.Replace(")
 		assert.Contains(t, err.Error(), "*fx_test.A already decorated")
 	})
 
@@ -0,0 +1,230 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.uber.org/fx/internal/fxreflect"
+	"go.uber.org/multierr"
+)
+
+// Runner runs goroutines for as long as the application is running. It is
+// provided to all Fx applications.
+//
+// Instead of starting a background loop from an OnStart hook and wiring its
+// cancellation and completion into an OnStop hook by hand, register it with
+// the Runner:
+//
+//	func NewConsumer(lc fx.Lifecycle, r fx.Runner, q *Queue) *Consumer {
+//	  c := &Consumer{q: q}
+//	  lc.Append(fx.StartHook(func() {
+//	    r.Go(c.consume, fx.ShutdownOnError())
+//	  }))
+//	  return c
+//	}
+//
+// The goroutine receives a context that is canceled when the application
+// begins to stop. Stop then waits for all goroutines to return, within the
+// stop deadline, before it runs OnStop hooks. Errors returned by goroutines,
+// other than context.Canceled after the application began to stop, are
+// returned by Stop.
+//
+// Goroutines registered from OnStart hooks, or while the application is
+// running, start right away. Goroutines registered before the application
+// starts, such as from a constructor, start once all OnStart hooks have
+// succeeded. Goroutines registered once Stop has begun, such as from an
+// OnStop hook, don't run unless the application is started again.
+type Runner interface {
+	Go(task func(context.Context) error, opts ...GoOption)
+}
+
+// GoOption configures a goroutine registered with Runner.Go.
+type GoOption interface {
+	applyGo(*runnerTask)
+}
+
+type shutdownOnErrorOption []ShutdownOption
+
+func (o shutdownOnErrorOption) applyGo(t *runnerTask) {
+	t.shutdownOpts = o
+	t.shutdownOnError = true
+}
+
+// ShutdownOnError is a GoOption that shuts down the application with
+// Shutdowner.Shutdown if the goroutine returns an error while the application
+// is running. The error is passed to Shutdown as the ShutdownCause, along
+// with the given options, and isn't returned by Stop. If the shutdown signal
+// can't be delivered, Stop returns the error instead.
+//
+//	r.Go(c.consume, fx.ShutdownOnError(fx.ExitCode(3)))
+func ShutdownOnError(opts ...ShutdownOption) GoOption {
+	return shutdownOnErrorOption(opts)
+}
+
+type runnerTask struct {
+	fn              func(context.Context) error
+	shutdownOnError bool
+	shutdownOpts    []ShutdownOption
+}
+
+type runner struct {
+	shutdowner Shutdowner
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc // nil unless starting or running
+	ctx     context.Context
+	pending []runnerTask         // registered while not starting or running
+	running map[*runnerTask]bool // goroutines that haven't returned
+	errs    []error              // errors returned by goroutines
+	done    chan struct{}        // signaled when a goroutine returns
+}
+
+var _ Runner = (*runner)(nil)
+
+func newRunner(shutdowner Shutdowner) *runner {
+	return &runner{
+		shutdowner: shutdowner,
+		running:    make(map[*runnerTask]bool),
+		done:       make(chan struct{}, 1),
+	}
+}
+
+func (r *runner) Go(fn func(context.Context) error, opts ...GoOption) {
+	task := runnerTask{fn: fn}
+	for _, opt := range opts {
+		opt.applyGo(&task)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel == nil {
+		r.pending = append(r.pending, task)
+		return
+	}
+	r.launch(task)
+}
+
+// launch runs the task on a new goroutine. r.mu must be held.
+func (r *runner) launch(task runnerTask) {
+	t := &task
+	r.running[t] = true
+	go func(ctx context.Context) {
+		err := t.fn(ctx)
+		stopping := ctx.Err() != nil
+		if err != nil && t.shutdownOnError && !stopping {
+			// The error is delivered as the cause of the shutdown,
+			// unless the signal couldn't be delivered.
+			opts := append([]ShutdownOption{ShutdownCause(err)}, t.shutdownOpts...)
+			if r.shutdowner.Shutdown(opts...) == nil {
+				err = nil
+			}
+		}
+
+		r.mu.Lock()
+		delete(r.running, t)
+		if err != nil && !(stopping && errors.Is(err, context.Canceled)) {
+			r.errs = append(r.errs, fmt.Errorf("goroutine %v failed: %w", fxreflect.FuncName(t.fn), err))
+		}
+		r.mu.Unlock()
+
+		select {
+		case r.done <- struct{}{}:
+		default:
+		}
+	}(r.ctx)
+}
+
+// start lets goroutines registered from now on run right away. It's a no-op
+// if the runner is already started.
+func (r *runner) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		return
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+}
+
+// launchPending starts the goroutines registered before start. It's a no-op
+// unless the runner is started.
+func (r *runner) launchPending() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel == nil {
+		return
+	}
+	for _, task := range r.pending {
+		r.launch(task)
+	}
+	r.pending = nil
+}
+
+// stop cancels the context of all running goroutines and waits for them to
+// return, or for ctx to be done. It returns the errors the goroutines
+// returned.
+func (r *runner) stop(ctx context.Context) error {
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	r.mu.Unlock()
+
+	for {
+		r.mu.Lock()
+		if len(r.running) == 0 {
+			err := multierr.Combine(r.errs...)
+			r.errs = nil
+			r.mu.Unlock()
+			return err
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			names := make([]string, 0, len(r.running))
+			for t := range r.running {
+				names = append(names, fxreflect.FuncName(t.fn))
+			}
+			sort.Strings(names)
+			err := multierr.Append(multierr.Combine(r.errs...), fmt.Errorf(
+				"goroutines did not return before the stop deadline: %v: %w", names, ctx.Err()))
+			r.errs = nil
+			return err
+		}
+	}
+}
+
+func (app *App) goRunner() Runner {
+	return app.runner
+}
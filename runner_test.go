@@ -0,0 +1,300 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestRunner(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CanceledOnStop", func(t *testing.T) {
+		t.Parallel()
+
+		var returned bool
+		stopped := make(chan struct{})
+		app := fxtest.New(t,
+			fx.Invoke(func(lc fx.Lifecycle, r fx.Runner) {
+				lc.Append(fx.StartHook(func() {
+					r.Go(func(ctx context.Context) error {
+						<-ctx.Done()
+						returned = true
+						close(stopped)
+						return ctx.Err()
+					})
+				}))
+				lc.Append(fx.StopHook(func() {
+					assert.True(t, returned, "goroutines must return before OnStop hooks run")
+				}))
+			}),
+		)
+		app.RequireStart()
+
+		select {
+		case <-stopped:
+			t.Fatal("goroutine must run until the application stops")
+		default:
+		}
+
+		app.RequireStop()
+		assert.True(t, returned)
+	})
+
+	t.Run("RegisteredBeforeStart", func(t *testing.T) {
+		t.Parallel()
+
+		started := make(chan struct{})
+		var r fx.Runner
+		app := fxtest.New(t, fx.Populate(&r))
+		r.Go(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		})
+
+		select {
+		case <-started:
+			t.Fatal("goroutine must not run before the application starts")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		app.RequireStart()
+		<-started
+		app.RequireStop()
+	})
+
+	t.Run("RegisteredBeforeStartWaitsForOnStart", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		ran := make(chan struct{})
+		app := fxtest.New(t,
+			fx.Invoke(func(lc fx.Lifecycle, r fx.Runner) {
+				r.Go(func(context.Context) error {
+					calls = append(calls, "registered")
+					close(ran)
+					return nil
+				})
+				lc.Append(fx.StartHook(func() {
+					r.Go(func(context.Context) error { return nil })
+					calls = append(calls, "start")
+				}))
+			}),
+		)
+		app.RequireStart()
+		<-ran
+		app.RequireStop()
+		assert.Equal(t, []string{"start", "registered"}, calls)
+	})
+
+	t.Run("RegisteredBeforeFailedStart", func(t *testing.T) {
+		t.Parallel()
+
+		app := fx.New(
+			fx.NopLogger,
+			fx.Invoke(func(lc fx.Lifecycle, r fx.Runner) {
+				r.Go(func(context.Context) error {
+					assert.Fail(t, "goroutine must not run if OnStart fails")
+					return nil
+				})
+				lc.Append(fx.StartHook(func() error {
+					return errors.New("great sadness")
+				}))
+			}),
+		)
+		require.Error(t, app.Start(context.Background()))
+	})
+
+	t.Run("RegisteredWhileStopping", func(t *testing.T) {
+		t.Parallel()
+
+		ran := make(chan struct{})
+		app := fxtest.New(t,
+			fx.Invoke(func(lc fx.Lifecycle, r fx.Runner) {
+				lc.Append(fx.StopHook(func() {
+					r.Go(func(ctx context.Context) error {
+						close(ran)
+						<-ctx.Done()
+						return nil
+					})
+				}))
+			}),
+		)
+		app.RequireStart().RequireStop()
+
+		select {
+		case <-ran:
+			t.Fatal("goroutine registered while stopping must not run")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		app.RequireStart()
+		<-ran
+		app.RequireStop()
+	})
+
+	t.Run("StartWhileStopping", func(t *testing.T) {
+		t.Parallel()
+
+		var app *fx.App
+		ran := make(chan struct{})
+		app = fx.New(
+			fx.NopLogger,
+			fx.Invoke(func(lc fx.Lifecycle, r fx.Runner) {
+				lc.Append(fx.StopHook(func() {
+					var serr *fx.StateError
+					assert.ErrorAs(t, app.Start(context.Background()), &serr)
+
+					r.Go(func(context.Context) error {
+						close(ran)
+						return nil
+					})
+				}))
+			}),
+		)
+		require.NoError(t, app.Start(context.Background()))
+		require.NoError(t, app.Stop(context.Background()))
+
+		select {
+		case <-ran:
+			t.Fatal("a failed Start must not start the Runner")
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+
+	t.Run("ErrorsReturnedByStop", func(t *testing.T) {
+		t.Parallel()
+
+		var r fx.Runner
+		app := fxtest.New(t, fx.Populate(&r))
+		app.RequireStart()
+
+		done := make(chan struct{})
+		r.Go(func(context.Context) error {
+			defer close(done)
+			return errors.New("great sadness")
+		})
+		<-done
+
+		err := app.Stop(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("StopDeadline", func(t *testing.T) {
+		t.Parallel()
+
+		var r fx.Runner
+		app := fx.New(fx.Populate(&r))
+		require.NoError(t, app.Start(context.Background()))
+
+		release := make(chan struct{})
+		defer close(release)
+		r.Go(func(context.Context) error {
+			<-release
+			return nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := app.Stop(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "goroutines did not return before the stop deadline")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ShutdownOnError", func(t *testing.T) {
+		t.Parallel()
+
+		var r fx.Runner
+		app := fxtest.New(t, fx.Populate(&r))
+		wait := app.Wait()
+		app.RequireStart()
+
+		sadness := errors.New("great sadness")
+		r.Go(func(context.Context) error {
+			return sadness
+		}, fx.ShutdownOnError(fx.ExitCode(3)))
+
+		sig := <-wait
+		assert.ErrorIs(t, sig.Err, sadness)
+		assert.Equal(t, 3, sig.ExitCode)
+
+		assert.NoError(t, app.Stop(context.Background()),
+			"the error must be reported only as the shutdown cause")
+	})
+
+	t.Run("ShutdownOnErrorUndelivered", func(t *testing.T) {
+		t.Parallel()
+
+		var r fx.Runner
+		app := fxtest.New(t, fx.Populate(&r))
+		done := app.Done()
+		app.RequireStart()
+
+		// Fill the Done channel so that the next signal can't be
+		// delivered.
+		var s fx.Shutdowner
+		require.NoError(t, app.Resolve(&s))
+		require.NoError(t, s.Shutdown())
+
+		returned := make(chan struct{})
+		sadness := errors.New("great sadness")
+		r.Go(func(context.Context) error {
+			defer close(returned)
+			return sadness
+		}, fx.ShutdownOnError())
+		<-returned
+		<-done
+
+		assert.ErrorIs(t, app.Stop(context.Background()), sadness)
+	})
+
+	t.Run("NoShutdownWhenStopping", func(t *testing.T) {
+		t.Parallel()
+
+		var r fx.Runner
+		app := fxtest.New(t, fx.Populate(&r))
+		done := app.Done()
+		app.RequireStart()
+
+		r.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, fx.ShutdownOnError())
+		app.RequireStop()
+
+		select {
+		case sig := <-done:
+			t.Fatalf("unexpected shutdown signal %v", sig)
+		default:
+		}
+	})
+}
@@ -23,49 +23,137 @@ package fx
 import (
 	"fmt"
 	"os"
+	"os/signal"
+	"time"
 )
 
 // Shutdowner provides a method that can manually trigger the shutdown of the
-// application by sending a signal to all open Done channels. Shutdowner works
-// on applications using Run as well as Start, Done, and Stop. The Shutdowner is
-// provided to all Fx applications.
+// application by sending a signal to all open Done and Wait channels.
+// Shutdowner works on applications using Run as well as Start, Done (or Wait),
+// and Stop. The Shutdowner is provided to all Fx applications.
 type Shutdowner interface {
 	Shutdown(...ShutdownOption) error
 }
 
 // ShutdownOption provides a way to configure properties of the shutdown
-// process. Currently, no options have been implemented.
+// process.
 type ShutdownOption interface {
 	apply(*shutdowner)
 }
 
+type exitCodeOption int
+
+func (code exitCodeOption) apply(s *shutdowner) {
+	s.exitCode = int(code)
+}
+
+var _ ShutdownOption = exitCodeOption(0)
+
+// ExitCode is a ShutdownOption that may be passed to the Shutdown method of
+// the Shutdowner interface. It specifies the exit code with which App.Run
+// terminates the process once the application has stopped.
+//
+// If the application fails to stop cleanly, App.Run exits with a non-zero
+// code regardless of the code requested here.
+func ExitCode(code int) ShutdownOption {
+	return exitCodeOption(code)
+}
+
+type shutdownTimeoutOption time.Duration
+
+func (to shutdownTimeoutOption) apply(s *shutdowner) {
+	s.timeout = time.Duration(to)
+}
+
+var _ ShutdownOption = shutdownTimeoutOption(0)
+
+// ShutdownTimeout is a ShutdownOption that may be passed to the Shutdown
+// method of the Shutdowner interface. It specifies how long App.Run waits for
+// the application to stop after this shutdown, in place of the StopTimeout.
+func ShutdownTimeout(timeout time.Duration) ShutdownOption {
+	return shutdownTimeoutOption(timeout)
+}
+
+type shutdownCauseOption struct{ err error }
+
+func (c shutdownCauseOption) apply(s *shutdowner) {
+	s.cause = c.err
+}
+
+var _ ShutdownOption = shutdownCauseOption{}
+
+// ShutdownCause is a ShutdownOption that may be passed to the Shutdown method
+// of the Shutdowner interface. It records why the application is shutting
+// down. The cause is delivered to Wait channels as the Err of the
+// ShutdownSignal, and App.Run reports it on the fxevent.Stopping event.
+func ShutdownCause(err error) ShutdownOption {
+	return shutdownCauseOption{err: err}
+}
+
 type shutdowner struct {
-	app *App
+	app      *App
+	exitCode int
+	timeout  time.Duration
+	cause    error
 }
 
-// Shutdown broadcasts a signal to all of the application's Done channels
-// and begins the Stop process. Applications can be shut down only after they
-// have finished starting up.
+// Shutdown broadcasts a signal to all of the application's Done and Wait
+// channels and begins the Stop process. Applications can be shut down only
+// after they have finished starting up.
 // In practice this means Shutdowner.Shutdown should not be called from an
 // fx.Invoke, but from a fx.Lifecycle.OnStart hook.
 func (s *shutdowner) Shutdown(opts ...ShutdownOption) error {
-	return s.app.broadcastSignal(_sigTERM)
+	// Options apply to this call only, so don't modify the shared
+	// shutdowner.
+	sd := shutdowner{app: s.app}
+	for _, opt := range opts {
+		opt.apply(&sd)
+	}
+
+	return s.app.broadcastSignal(ShutdownSignal{
+		Signal:   _sigTERM,
+		ExitCode: sd.exitCode,
+		Err:      sd.cause,
+	}, sd.timeout)
 }
 
 func (app *App) shutdowner() Shutdowner {
 	return &shutdowner{app: app}
 }
 
-func (app *App) broadcastSignal(signal os.Signal) error {
+// ShutdownSignal is delivered to the application's Wait channels when the
+// application is asked to shut down.
+//
+// If shutdown was requested with Shutdowner.Shutdown, ExitCode and Err hold
+// the values passed to the ExitCode and ShutdownCause options, if any. If the
+// application received an operating system signal, only Signal is populated.
+type ShutdownSignal struct {
+	// Signal is the signal that caused the shutdown.
+	Signal os.Signal
+
+	// ExitCode is the exit code the application should exit with.
+	ExitCode int
+
+	// Err is the reason for the shutdown, if one was given.
+	Err error
+}
+
+// String renders the ShutdownSignal as its underlying signal.
+func (sig ShutdownSignal) String() string {
+	return fmt.Sprintf("%v", sig.Signal)
+}
+
+func (app *App) broadcastSignal(sig ShutdownSignal, stopTimeout time.Duration) error {
 	app.donesMu.Lock()
 	defer app.donesMu.Unlock()
 
-	app.shutdownSig = signal
+	app.shutdownSig = &sig
+	app.shutdownTimeout = stopTimeout
 
 	var unsent int
 	for _, done := range app.dones {
 		select {
-		case done <- signal:
+		case done <- sig.Signal:
 		default:
 			// shutdown called when done channel has already received a
 			// termination signal that has not been cleared
@@ -73,11 +161,111 @@ func (app *App) broadcastSignal(signal os.Signal) error {
 		}
 	}
 
+	for _, wait := range app.waits {
+		select {
+		case wait <- sig:
+		default:
+			unsent++
+		}
+	}
+
 	if unsent != 0 {
 		return fmt.Errorf("failed to send %v signal to %v out of %v channels",
-			signal, unsent, len(app.dones),
+			sig.Signal, unsent, len(app.dones)+len(app.waits),
 		)
 	}
 
 	return nil
 }
+
+// shutdownRequest returns the signal broadcast by the most recent call to
+// Shutdowner.Shutdown, and the stop timeout requested with it. Both are zero
+// if Shutdown wasn't called.
+func (app *App) shutdownRequest() (sig ShutdownSignal, stopTimeout time.Duration) {
+	app.donesMu.Lock()
+	defer app.donesMu.Unlock()
+
+	if app.shutdownSig == nil {
+		return ShutdownSignal{}, 0
+	}
+	return *app.shutdownSig, app.shutdownTimeout
+}
+
+// resetShutdown forgets the application's Done and Wait channels and any
+// shutdown requested with Shutdowner.Shutdown, so that a restarted
+// application waits for a new shutdown signal.
+func (app *App) resetShutdown() {
+	app.donesMu.Lock()
+	defer app.donesMu.Unlock()
+
+	for _, done := range app.dones {
+		signal.Stop(done)
+	}
+	app.stopIgnoringSignals()
+	app.dones = nil
+	app.waits = nil
+	app.shutdownSig = nil
+	app.shutdownTimeout = 0
+}
+
+// signalRelay forwards operating system signals to the application's Wait
+// channels. Unlike Done channels, Wait channels cannot be registered with
+// signal.Notify directly.
+type signalRelay struct {
+	signals chan os.Signal
+	stop    chan struct{} // closed to stop the relay
+	stopped chan struct{} // closed when the relay has stopped
+}
+
+// startSignalRelay starts relaying operating system signals to Wait channels
+// if it isn't already doing so. The caller must hold donesMu.
+func (app *App) startSignalRelay() {
+	if app.sigRelay != nil {
+		return
+	}
+
+	r := &signalRelay{
+		signals: make(chan os.Signal, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	app.notifyShutdownSignals(r.signals)
+	app.sigRelay = r
+
+	go func() {
+		defer close(r.stopped)
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case sig := <-r.signals:
+				app.donesMu.Lock()
+				for _, wait := range app.waits {
+					select {
+					case wait <- ShutdownSignal{Signal: sig}:
+					default:
+					}
+				}
+				app.donesMu.Unlock()
+			}
+		}
+	}()
+}
+
+// stopSignalRelay stops relaying operating system signals to Wait channels,
+// waiting for the relay to exit.
+func (app *App) stopSignalRelay() {
+	app.donesMu.Lock()
+	r := app.sigRelay
+	app.sigRelay = nil
+	app.donesMu.Unlock()
+
+	if r == nil {
+		return
+	}
+
+	signal.Stop(r.signals)
+	close(r.stop)
+	<-r.stopped
+}
@@ -22,6 +22,7 @@ package fx_test
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 
@@ -69,6 +70,82 @@ func TestShutdown(t *testing.T) {
 		assert.NotNil(t, <-done, "done channel did not receive signal")
 	})
 
+	t.Run("BroadcastsToWaitChannels", func(t *testing.T) {
+		t.Parallel()
+
+		var s fx.Shutdowner
+		app := fxtest.New(
+			t,
+			fx.Populate(&s),
+		)
+
+		wait1, wait2 := app.Wait(), app.Wait()
+		done := app.Done()
+		defer app.RequireStart().RequireStop()
+
+		assert.NoError(t, s.Shutdown(fx.ExitCode(2)), "error in app shutdown")
+		for i, wait := range []<-chan fx.ShutdownSignal{wait1, wait2} {
+			sig := <-wait
+			assert.NotNil(t, sig.Signal, "wait channel %v did not receive signal", i+1)
+			assert.Equal(t, 2, sig.ExitCode, "wait channel %v received wrong exit code", i+1)
+		}
+		assert.NotNil(t, <-done, "done channel did not receive signal")
+	})
+
+	t.Run("DeliversCauseToWaitChannels", func(t *testing.T) {
+		t.Parallel()
+
+		var s fx.Shutdowner
+		app := fxtest.New(
+			t,
+			fx.Populate(&s),
+		)
+
+		wait := app.Wait()
+		defer app.RequireStart().RequireStop()
+
+		cause := errors.New("health check failed")
+		assert.NoError(t, s.Shutdown(fx.ShutdownCause(cause)), "error in app shutdown")
+		assert.Equal(t, cause, (<-wait).Err, "wait channel received wrong cause")
+	})
+
+	t.Run("ErrorOnUnsentWaitSignal", func(t *testing.T) {
+		t.Parallel()
+
+		var s fx.Shutdowner
+		app := fxtest.New(
+			t,
+			fx.Populate(&s),
+		)
+
+		wait := app.Wait()
+		defer app.RequireStart().RequireStop()
+		assert.NoError(t, s.Shutdown(), "error returned from first shutdown call")
+
+		assert.EqualError(t, s.Shutdown(), "failed to send terminated signal to 1 out of 1 channels",
+			"unexpected error returned when shutdown is called with a blocked channel")
+		assert.NotNil(t, (<-wait).Signal, "wait channel did not receive signal")
+	})
+
+	t.Run("shutdown app before calling Wait()", func(t *testing.T) {
+		t.Parallel()
+
+		var s fx.Shutdowner
+		app := fxtest.New(
+			t,
+			fx.Populate(&s),
+		)
+
+		require.NoError(t, app.Start(context.Background()), "error starting app")
+		assert.NoError(t, s.Shutdown(fx.ExitCode(3)), "error in app shutdown")
+		wait1, wait2 := app.Wait(), app.Wait()
+		defer app.Stop(context.Background())
+		// Receiving on wait1 and wait2 will deadlock in the event that
+		// app.Wait() doesn't work as expected.
+		assert.Equal(t, 3, (<-wait1).ExitCode, "wait channel 1 received wrong exit code")
+		assert.Equal(t, 3, (<-wait2).ExitCode, "wait channel 2 received wrong exit code")
+	})
+
 	t.Run("shutdown app before calling Done()", func(t *testing.T) {
 		t.Parallel()
 
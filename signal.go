@@ -0,0 +1,231 @@
+// Copyright (c) 2022 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fx
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+)
+
+// _defaultShutdownSignals shut applications down unless ShutdownSignals is
+// used.
+var _defaultShutdownSignals = []os.Signal{os.Interrupt, _sigINT, _sigTERM}
+
+// ShutdownSignals changes the operating system signals that shut the
+// application down. By default, applications shut down on SIGINT and
+// SIGTERM.
+//
+// The signals passed here replace the default set. For example, to also shut
+// down on SIGQUIT,
+//
+//	fx.ShutdownSignals(unix.SIGINT, unix.SIGTERM, unix.SIGQUIT)
+//
+// SIGINT and SIGTERM are ignored if they're left out, for as long as the
+// application waits on Done, Wait, or Run, rather than terminating the
+// process without running OnStop hooks. For example, to shut down only on
+// SIGTERM and ignore SIGINT,
+//
+//	fx.ShutdownSignals(unix.SIGTERM)
+//
+// With no arguments, no operating system signal shuts the application down,
+// and only Shutdowner does. To act on a signal without shutting down, use
+// SignalHandlers instead.
+func ShutdownSignals(signals ...os.Signal) Option {
+	return shutdownSignalsOption(signals)
+}
+
+type shutdownSignalsOption []os.Signal
+
+func (o shutdownSignalsOption) apply(m *module) {
+	if m.parent != nil {
+		m.app.err = fmt.Errorf("fx.ShutdownSignals Option should be passed to top-level App, " +
+			"not to fx.Module")
+	} else {
+		m.app.shutdownSignals = []os.Signal(o)
+	}
+}
+
+func (o shutdownSignalsOption) String() string {
+	items := make([]string, len(o))
+	for i, sig := range o {
+		items[i] = sig.String()
+	}
+	return fmt.Sprintf("fx.ShutdownSignals(%s)", strings.Join(items, ", "))
+}
+
+// notifyShutdownSignals relays the operating system signals that shut the
+// application down to c, and ignores the default shutdown signals that
+// aren't among them. The caller must hold donesMu.
+func (app *App) notifyShutdownSignals(c chan<- os.Signal) {
+	// signal.Notify relays all signals if none are given.
+	if len(app.shutdownSignals) > 0 {
+		signal.Notify(c, app.shutdownSignals...)
+	}
+
+	if app.ignoredSignals != nil {
+		return
+	}
+
+	var ignored []os.Signal
+	for _, sig := range _defaultShutdownSignals {
+		if !containsSignal(app.shutdownSignals, sig) {
+			ignored = append(ignored, sig)
+		}
+	}
+	if len(ignored) == 0 {
+		return
+	}
+
+	// Unlike signal.Ignore, this doesn't affect other listeners for these
+	// signals. Signals aren't sent to a full channel, so nothing needs to
+	// read from it.
+	app.ignoredSignals = make(chan os.Signal, 1)
+	signal.Notify(app.ignoredSignals, ignored...)
+}
+
+// stopIgnoringSignals restores the handling of the signals ignored by
+// notifyShutdownSignals. The caller must hold donesMu.
+func (app *App) stopIgnoringSignals() {
+	if app.ignoredSignals != nil {
+		signal.Stop(app.ignoredSignals)
+		app.ignoredSignals = nil
+	}
+}
+
+func containsSignal(signals []os.Signal, sig os.Signal) bool {
+	for _, s := range signals {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// SignalHandlers allows constructors to act on operating system signals that
+// don't shut the application down, such as SIGHUP to reload configuration
+// or SIGUSR1 to dump diagnostics. It is provided to all Fx applications.
+//
+// Signals are delivered to the handlers only while the application is
+// running: from the time Start succeeds until Stop is called.
+type SignalHandlers interface {
+	Append(SignalHandler)
+}
+
+// A SignalHandler is called with each of the given operating system signals
+// that the application receives while it's running.
+//
+// Handlers are called one at a time, on a goroutine owned by the
+// application, so they should return promptly. Stop waits for a running
+// handler to return before it runs any OnStop hooks.
+//
+// If a signal is both handled and in the set passed to ShutdownSignals, its
+// handlers are called and the application shuts down.
+type SignalHandler struct {
+	Signals  []os.Signal
+	OnSignal func(os.Signal)
+}
+
+type signalHandlers struct {
+	mu       sync.Mutex
+	handlers []SignalHandler
+	relay    *signalRelay // non-nil while delivering signals
+}
+
+var _ SignalHandlers = (*signalHandlers)(nil)
+
+func (sh *signalHandlers) Append(h SignalHandler) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	sh.handlers = append(sh.handlers, h)
+}
+
+// start begins delivering signals to the handlers, if there are any.
+func (sh *signalHandlers) start() {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if sh.relay != nil || len(sh.handlers) == 0 {
+		return
+	}
+
+	var signals []os.Signal
+	for _, h := range sh.handlers {
+		signals = append(signals, h.Signals...)
+	}
+	if len(signals) == 0 {
+		return
+	}
+
+	handlers := sh.handlers
+	r := &signalRelay{
+		signals: make(chan os.Signal, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	signal.Notify(r.signals, signals...)
+	sh.relay = r
+
+	go func() {
+		defer close(r.stopped)
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case sig := <-r.signals:
+				for _, h := range handlers {
+					if handlesSignal(h, sig) {
+						h.OnSignal(sig)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// stop stops delivering signals to the handlers, waiting for any running
+// handler to return.
+func (sh *signalHandlers) stop() {
+	sh.mu.Lock()
+	r := sh.relay
+	sh.relay = nil
+	sh.mu.Unlock()
+
+	if r == nil {
+		return
+	}
+
+	signal.Stop(r.signals)
+	close(r.stop)
+	<-r.stopped
+}
+
+func handlesSignal(h SignalHandler, sig os.Signal) bool {
+	return containsSignal(h.Signals, sig)
+}
+
+func (app *App) signalHandlerRegistry() SignalHandlers {
+	return app.signalHandlers
+}
@@ -78,10 +78,54 @@ import (
 //		fx.Annotate(handler, fx.As(new(http.Handler))),
 //	)
 func Supply(values ...interface{}) Option {
+	return supply(values, fxreflect.CallerStack(1, 0))
+}
+
+// SupplyWithHooks is like Supply, but also appends lifecycle hooks for the
+// Start, Stop and Close methods of the supplied values, as if each value had
+// been annotated with AutoHooks.
+//
+//	conn := db.Open(...)
+//	fx.SupplyWithHooks(conn) // conn.Close runs when the application stops
+func SupplyWithHooks(values ...interface{}) Option {
+	withHooks := make([]interface{}, len(values))
+	for i, value := range values {
+		switch value := value.(type) {
+		case annotationError:
+			withHooks[i] = value
+		case annotated:
+			value.AutoHooks = true
+			withHooks[i] = value
+		case Annotated:
+			if value.Name != "" && value.Group != "" {
+				// Reported as invalid when the value is provided.
+				withHooks[i] = value
+				break
+			}
+			ann := annotated{Target: value.Target, AutoHooks: true}
+			switch {
+			case value.Name != "":
+				ann.ResultTags = []string{fmt.Sprintf("name:%q", value.Name)}
+			case value.Group != "":
+				ann.ResultTags = []string{fmt.Sprintf("group:%q", value.Group)}
+			}
+			withHooks[i] = ann
+		default:
+			withHooks[i] = annotated{Target: value, AutoHooks: true}
+		}
+	}
+	return supply(withHooks, fxreflect.CallerStack(1, 0))
+}
+
+func supply(values []interface{}, stack fxreflect.Stack) Option {
 	constructors := make([]interface{}, len(values)) // one function per value
 	types := make([]reflect.Type, len(values))
 	for i, value := range values {
 		switch value := value.(type) {
+		case annotationError:
+			// Reported when the value is provided.
+			constructors[i] = value
+			_, types[i] = newSupplyConstructor(value.target)
 		case annotated:
 			var typ reflect.Type
 			value.Target, typ = newSupplyConstructor(value.Target)
@@ -100,7 +144,7 @@ func Supply(values ...interface{}) Option {
 	return supplyOption{
 		Targets: constructors,
 		Types:   types,
-		Stack:   fxreflect.CallerStack(1, 0),
+		Stack:   stack,
 	}
 }
 
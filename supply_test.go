@@ -21,6 +21,7 @@
 package fx_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -195,3 +196,76 @@ func TestSupply(t *testing.T) {
 		defer app.RequireStart().RequireStop()
 	})
 }
+
+type supplyConn struct {
+	closed bool
+}
+
+func (c *supplyConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestSupplyWithHooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("values", func(t *testing.T) {
+		t.Parallel()
+
+		a, b, c := new(supplyConn), new(supplyConn), new(supplyConn)
+		var got struct {
+			fx.In
+
+			A *supplyConn
+			B *supplyConn   `name:"b"`
+			C []*supplyConn `group:"c"`
+		}
+		app, spy := NewSpied(
+			fx.SupplyWithHooks(
+				a,
+				fx.Annotated{Name: "b", Target: b},
+				fx.Annotate(c, fx.ResultTags(`group:"c"`)),
+			),
+			fx.Populate(&got),
+		)
+		require.NoError(t, app.Err())
+		require.NoError(t, app.Start(context.Background()))
+		require.NoError(t, app.Stop(context.Background()))
+
+		assert.Same(t, a, got.A)
+		assert.Same(t, b, got.B)
+		assert.Equal(t, []*supplyConn{c}, got.C)
+		assert.True(t, a.closed)
+		assert.True(t, b.closed)
+		assert.True(t, c.closed)
+
+		for _, ev := range spy.Events().SelectByTypeName("OnStopExecuting") {
+			assert.Equal(t, "go.uber.org/fx_test.TestSupplyWithHooks.func1",
+				ev.(*fxevent.OnStopExecuting).CallerName)
+		}
+	})
+
+	t.Run("values without hooks", func(t *testing.T) {
+		t.Parallel()
+
+		type A struct{}
+
+		var a *A
+		app := fxtest.New(t,
+			fx.SupplyWithHooks(&A{}),
+			fx.Populate(&a),
+		)
+		app.RequireStart().RequireStop()
+		assert.NotNil(t, a)
+	})
+
+	t.Run("invalid Annotated", func(t *testing.T) {
+		t.Parallel()
+
+		app := NewForTest(t,
+			fx.SupplyWithHooks(fx.Annotated{Name: "a", Group: "b", Target: new(supplyConn)}),
+		)
+		require.Error(t, app.Err())
+		assert.Contains(t, app.Err().Error(), "fx.Annotated may specify only one of Name or Group")
+	})
+}